@@ -18,10 +18,12 @@ import (
 	"encoding/binary"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/tsdb/index/roaring"
 )
 
 var allPostingsKey = labels.Label{}
@@ -31,6 +33,13 @@ func AllPostingsKey() (name, value string) {
 	return allPostingsKey.Name, allPostingsKey.Value
 }
 
+// AllPostingsLabel returns the sentinel label used to store the postings list of all
+// existing IDs as a labels.Label, for callers that build raw postings lists outside of
+// this package and need to reference the same sentinel MemPostings uses internally.
+func AllPostingsLabel() labels.Label {
+	return allPostingsKey
+}
+
 // MemPostings holds postings list for series ID per label pair. They may be written
 // to out of order.
 // ensureOrder() must be called once before any reads are done. This allows for quick
@@ -39,13 +48,32 @@ type MemPostings struct {
 	mtx     sync.RWMutex
 	m       map[string]map[string][]uint64
 	ordered bool
+
+	// nameVersion and nameUnionCache back LabelValuesUnion: nameVersion is
+	// bumped whenever a postings list under a name is touched, and a cached
+	// union is only trusted while its version still matches.
+	nameVersion    map[string]uint64
+	nameUnionCache map[string]nameUnion
+
+	// series is the inverse of m: it tracks the label set each id was added
+	// with, so Delete can remove an id from just the postings lists that
+	// actually reference it instead of scanning every list in the index.
+	series map[uint64]labels.Labels
+}
+
+type nameUnion struct {
+	version uint64
+	ids     []uint64
 }
 
 // NewMemPostings returns a memPostings that's ready for reads and writes.
 func NewMemPostings() *MemPostings {
 	return &MemPostings{
-		m:       make(map[string]map[string][]uint64, 512),
-		ordered: true,
+		m:              make(map[string]map[string][]uint64, 512),
+		ordered:        true,
+		nameVersion:    map[string]uint64{},
+		nameUnionCache: map[string]nameUnion{},
+		series:         map[uint64]labels.Labels{},
 	}
 }
 
@@ -53,8 +81,11 @@ func NewMemPostings() *MemPostings {
 // until ensureOrder was called once.
 func NewUnorderedMemPostings() *MemPostings {
 	return &MemPostings{
-		m:       make(map[string]map[string][]uint64, 512),
-		ordered: false,
+		m:              make(map[string]map[string][]uint64, 512),
+		ordered:        false,
+		nameVersion:    map[string]uint64{},
+		nameUnionCache: map[string]nameUnion{},
+		series:         map[uint64]labels.Labels{},
 	}
 }
 
@@ -85,6 +116,13 @@ type PostingsStats struct {
 	CardinalityLabelStats   []Stat
 	LabelValueStats         []Stat
 	LabelValuePairsStats    []Stat
+	// LabelsPerSeriesStats holds the series (keyed by their ID, formatted as
+	// a string) with the most labels attached, Count being their label
+	// count. It's sourced from the series label sets Add records, so it's
+	// only as complete as that bookkeeping - series added before that
+	// bookkeeping existed would be absent, but every series in a live index
+	// has one.
+	LabelsPerSeriesStats []Stat
 }
 
 // Stats calculates the cardinality statistics from postings.
@@ -98,11 +136,13 @@ func (p *MemPostings) Stats(label string) *PostingsStats {
 	labels := &maxHeap{}
 	labelValueLength := &maxHeap{}
 	labelValuePairs := &maxHeap{}
+	labelsPerSeries := &maxHeap{}
 
 	metrics.init(maxNumOfRecords)
 	labels.init(maxNumOfRecords)
 	labelValueLength.init(maxNumOfRecords)
 	labelValuePairs.init(maxNumOfRecords)
+	labelsPerSeries.init(maxNumOfRecords)
 
 	for n, e := range p.m {
 		if n == "" {
@@ -120,17 +160,26 @@ func (p *MemPostings) Stats(label string) *PostingsStats {
 		labelValueLength.push(Stat{Name: n, Count: size})
 	}
 
+	for id, lset := range p.series {
+		labelsPerSeries.push(Stat{Name: strconv.FormatUint(id, 10), Count: uint64(len(lset))})
+	}
+
 	p.mtx.RUnlock()
 
 	return &PostingsStats{
 		CardinalityMetricsStats: metrics.get(),
 		CardinalityLabelStats:   labels.get(),
 		LabelValueStats:         labelValueLength.get(),
+		LabelsPerSeriesStats:    labelsPerSeries.get(),
 		LabelValuePairsStats:    labelValuePairs.get(),
 	}
 }
 
-// Get returns a postings list for the given label pair.
+// Get returns a postings list for the given label pair. The list is copied
+// under lock, so it remains a valid, consistent snapshot even if the caller
+// holds onto it across later Add or Delete calls: those mutate or replace
+// p.m[name][value] in place, which would otherwise race with, or silently
+// change, the value an in-progress read observes.
 func (p *MemPostings) Get(name, value string) Postings {
 	var lp []uint64
 	p.mtx.RLock()
@@ -138,21 +187,34 @@ func (p *MemPostings) Get(name, value string) Postings {
 	if l != nil {
 		lp = l[value]
 	}
+	list := make([]uint64, len(lp))
+	copy(list, lp)
 	p.mtx.RUnlock()
 
 	if lp == nil {
 		return EmptyPostings()
 	}
-	return newListPostings(lp...)
+	return newListPostings(list...)
 }
 
-// All returns a postings list over all documents ever added.
+// All returns a postings list over every series ID added to p so far, in
+// ascending order (once EnsureOrder has been called on an index built with
+// NewUnorderedMemPostings). Because it goes through Get, the list is read
+// and copied under lock, so it reflects every Add that happened-before this
+// call and is unaffected by any Add or Delete afterwards.
 func (p *MemPostings) All() Postings {
 	return p.Get(AllPostingsKey())
 }
 
 // EnsureOrder ensures that all postings lists are sorted. After it returns all further
 // calls to add and addFor will insert new IDs in a sorted manner.
+//
+// It already does the minimum amount of work a continuously-ingesting index
+// needs: once p.ordered is true, addFor's own insertion-sort repair pass
+// keeps every list sorted incrementally as ids are added, one swap pass per
+// Add rather than a full re-sort, so there is no whole-index work left for
+// EnsureOrder to redo on a later call, and a repeat call is a cheap no-op
+// that returns immediately below.
 func (p *MemPostings) EnsureOrder() {
 	p.mtx.Lock()
 	defer p.mtx.Unlock()
@@ -187,62 +249,175 @@ func (p *MemPostings) EnsureOrder() {
 	p.ordered = true
 }
 
-// Delete removes all ids in the given map from the postings lists.
-func (p *MemPostings) Delete(deleted map[uint64]struct{}) {
-	var keys, vals []string
-
-	// Collect all keys relevant for deletion once. New keys added afterwards
-	// can by definition not be affected by any of the given deletes.
+// Clone returns a deep copy of p that can be read from independently of
+// concurrent Add/Delete calls against the original, without holding p's
+// lock for the query's lifetime. It takes the same approach Get already
+// takes for a single postings list - copy once under lock, then let the
+// two diverge - rather than the lazy, refcounted copy-on-write machinery a
+// literal per-container interpretation would need: index-wide clones are
+// rare enough (a long-running query, not every read) that one upfront copy
+// is the simpler and cheaper trade.
+func (p *MemPostings) Clone() *MemPostings {
 	p.mtx.RLock()
-	for n := range p.m {
-		keys = append(keys, n)
+	defer p.mtx.RUnlock()
+
+	m := make(map[string]map[string][]uint64, len(p.m))
+	for name, e := range p.m {
+		ne := make(map[string][]uint64, len(e))
+		for value, list := range e {
+			l := make([]uint64, len(list))
+			copy(l, list)
+			ne[value] = l
+		}
+		m[name] = ne
 	}
-	p.mtx.RUnlock()
 
-	for _, n := range keys {
-		p.mtx.RLock()
-		vals = vals[:0]
-		for v := range p.m[n] {
-			vals = append(vals, v)
+	// Label sets are never mutated in place after Add stores them, so they
+	// can be shared rather than copied.
+	series := make(map[uint64]labels.Labels, len(p.series))
+	for id, lset := range p.series {
+		series[id] = lset
+	}
+
+	return &MemPostings{
+		m:              m,
+		ordered:        p.ordered,
+		nameVersion:    map[string]uint64{},
+		nameUnionCache: map[string]nameUnion{},
+		series:         series,
+	}
+}
+
+// Delete removes all ids in the given map from the postings lists. It uses
+// the series label sets recorded by Add to touch only the postings lists
+// that actually reference each id, rather than scanning every list in the
+// index. If p.series has no record of id - e.g. DeleteLabelValue already
+// dropped it while decommissioning one of its label pairs - it falls back
+// to a full scan of p.m for that id, so any other label pairs still
+// referencing it are still cleaned up.
+func (p *MemPostings) Delete(deleted map[uint64]struct{}) {
+	for id := range deleted {
+		// Only lock for processing one id so we don't block reads for too long.
+		p.mtx.Lock()
+
+		lset, ok := p.series[id]
+		if !ok {
+			p.deleteFromAllLists(id)
+			p.mtx.Unlock()
+			continue
+		}
+		for _, l := range lset {
+			p.removeFor(id, l)
 		}
-		p.mtx.RUnlock()
+		p.removeFor(id, allPostingsKey)
+		delete(p.series, id)
 
-		// For each posting we first analyse whether the postings list is affected by the deletes.
-		// If yes, we actually reallocate a new postings list.
-		for _, l := range vals {
-			// Only lock for processing one postings list so we don't block reads for too long.
-			p.mtx.Lock()
+		p.mtx.Unlock()
+	}
+}
 
+// deleteFromAllLists removes id from every postings list in p.m. It's the
+// exhaustive fallback Delete uses when p.series doesn't know which labels
+// id belongs to. p.mtx must be held for writing.
+func (p *MemPostings) deleteFromAllLists(id uint64) {
+	for name, e := range p.m {
+		for value, list := range e {
 			found := false
-			for _, id := range p.m[n][l] {
-				if _, ok := deleted[id]; ok {
+			repl := make([]uint64, 0, len(list))
+			for _, x := range list {
+				if x == id {
 					found = true
-					break
+					continue
 				}
+				repl = append(repl, x)
 			}
 			if !found {
-				p.mtx.Unlock()
 				continue
 			}
-			repl := make([]uint64, 0, len(p.m[n][l]))
-
-			for _, id := range p.m[n][l] {
-				if _, ok := deleted[id]; !ok {
-					repl = append(repl, id)
-				}
-			}
 			if len(repl) > 0 {
-				p.m[n][l] = repl
+				e[value] = repl
 			} else {
-				delete(p.m[n], l)
+				delete(e, value)
 			}
-			p.mtx.Unlock()
+			p.nameVersion[name]++
 		}
-		p.mtx.Lock()
-		if len(p.m[n]) == 0 {
-			delete(p.m, n)
+		if len(e) == 0 {
+			delete(p.m, name)
+		}
+	}
+}
+
+// removeFor removes id from the postings list for l, deleting the list, and
+// the name entirely once it has no values left, if id was its last member.
+// p.mtx must be held for writing.
+func (p *MemPostings) removeFor(id uint64, l labels.Label) {
+	nm, ok := p.m[l.Name]
+	if !ok {
+		return
+	}
+	list, ok := nm[l.Value]
+	if !ok {
+		return
+	}
+	repl := make([]uint64, 0, len(list))
+	for _, x := range list {
+		if x != id {
+			repl = append(repl, x)
+		}
+	}
+	if len(repl) > 0 {
+		nm[l.Value] = repl
+	} else {
+		delete(nm, l.Value)
+		if len(nm) == 0 {
+			delete(p.m, l.Name)
+		}
+	}
+	p.nameVersion[l.Name]++
+}
+
+// DeleteLabelValue removes the postings list for the single name/value pair,
+// along with those ids from the all-postings list, without scanning any
+// other label's postings the way Delete does. It assumes the caller knows
+// the ids under name/value belong to no other series (e.g. a decommissioned
+// label value), so it does not hunt down and scrub those ids from other
+// label pairs that may still reference them; callers that need that
+// guarantee should use Delete instead.
+func (p *MemPostings) DeleteLabelValue(name, value string) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	e, ok := p.m[name]
+	if !ok {
+		return
+	}
+	ids, ok := e[value]
+	if !ok {
+		return
+	}
+	delete(e, value)
+	if len(e) == 0 {
+		delete(p.m, name)
+	}
+	p.nameVersion[name]++
+
+	removed := make(map[uint64]struct{}, len(ids))
+	for _, id := range ids {
+		removed[id] = struct{}{}
+	}
+
+	all := p.m[allPostingsKey.Name][allPostingsKey.Value]
+	repl := make([]uint64, 0, len(all))
+	for _, id := range all {
+		if _, ok := removed[id]; !ok {
+			repl = append(repl, id)
 		}
-		p.mtx.Unlock()
+	}
+	p.m[allPostingsKey.Name][allPostingsKey.Value] = repl
+	p.nameVersion[allPostingsKey.Name]++
+
+	for id := range removed {
+		delete(p.series, id)
 	}
 }
 
@@ -269,10 +444,61 @@ func (p *MemPostings) Add(id uint64, lset labels.Labels) {
 		p.addFor(id, l)
 	}
 	p.addFor(id, allPostingsKey)
+	p.series[id] = lset
 
 	p.mtx.Unlock()
 }
 
+// AddBatch adds every id in ids to the postings index under lset, all in a
+// single locked section. This is far cheaper than calling Add once per id
+// during WAL replay, since each Add call otherwise pays its own lock
+// acquisition. MemPostings stores plain []uint64 lists rather than a
+// roaring bitmap, so there is no AddRange to delegate to directly; instead,
+// addManyFor gets the equivalent win for the common replay case - ids
+// forming an ascending contiguous range - by recognizing that an
+// already-sorted batch appended after a list's current maximum needs no
+// repair pass at all.
+func (p *MemPostings) AddBatch(ids []uint64, lset labels.Labels) {
+	if len(ids) == 0 {
+		return
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	for _, l := range lset {
+		p.addManyFor(ids, l)
+	}
+	p.addManyFor(ids, allPostingsKey)
+
+	for _, id := range ids {
+		p.series[id] = lset
+	}
+}
+
+func (p *MemPostings) addManyFor(ids []uint64, l labels.Label) {
+	nm, ok := p.m[l.Name]
+	if !ok {
+		nm = map[string][]uint64{}
+		p.m[l.Name] = nm
+	}
+	prevLen := len(nm[l.Value])
+	list := append(nm[l.Value], ids...)
+	nm[l.Value] = list
+	p.nameVersion[l.Name]++
+
+	if !p.ordered {
+		return
+	}
+
+	appended := list[prevLen:]
+	sorted := sort.SliceIsSorted(appended, func(i, j int) bool { return appended[i] < appended[j] })
+	if sorted && (prevLen == 0 || list[prevLen-1] <= appended[0]) {
+		return
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a] < list[b] })
+}
+
 func (p *MemPostings) addFor(id uint64, l labels.Label) {
 	nm, ok := p.m[l.Name]
 	if !ok {
@@ -281,6 +507,7 @@ func (p *MemPostings) addFor(id uint64, l labels.Label) {
 	}
 	list := append(nm[l.Value], id)
 	nm[l.Value] = list
+	p.nameVersion[l.Name]++
 
 	if !p.ordered {
 		return
@@ -299,10 +526,37 @@ func (p *MemPostings) addFor(id uint64, l labels.Label) {
 
 // ExpandPostings returns the postings expanded as a slice.
 func ExpandPostings(p Postings) (res []uint64, err error) {
+	return ExpandPostingsInto(p, nil)
+}
+
+// CountPostings returns the number of elements p would yield, without
+// materializing them into a slice the way ExpandPostings does. If p is
+// backed by a roaring bitmap - e.g. the iterator returned by
+// roaring.NewRoaringBitmapIterator - it short-circuits to the bitmap's own
+// Cardinality instead of draining the iterator one element at a time. That
+// short-circuit assumes p hasn't already been partially consumed; call it
+// on a freshly built iterator, the same assumption nativeIntersect and
+// nativeMerge make about their own bitmap-backed inputs.
+func CountPostings(p Postings) (uint64, error) {
+	if bs, ok := p.(bitmapSource); ok {
+		return bs.Bitmap().Cardinality(), nil
+	}
+	var n uint64
+	for p.Next() {
+		n++
+	}
+	return n, p.Err()
+}
+
+// ExpandPostingsInto is like ExpandPostings but appends into buf instead of
+// allocating a fresh slice, so callers that expand many postings lists in a
+// loop can reuse one buffer across calls (e.g. buf[:0]) instead of paying
+// an allocation per call.
+func ExpandPostingsInto(p Postings, buf []uint64) ([]uint64, error) {
 	for p.Next() {
-		res = append(res, p.At())
+		buf = append(buf, p.At())
 	}
-	return res, p.Err()
+	return buf, p.Err()
 }
 
 // Postings provides iterative access over a postings list.
@@ -311,10 +565,15 @@ type Postings interface {
 	Next() bool
 
 	// Seek advances the iterator to value v or greater and returns
-	// true if a value was found.
+	// true if a value was found. If Seek returns false, the iterator is
+	// exhausted and At is undefined: implementations are free to leave it
+	// at whatever it last held, and callers must not rely on any
+	// particular value in that case.
 	Seek(v uint64) bool
 
-	// At returns the value at the current iterator position.
+	// At returns the value at the current iterator position. Its result is
+	// undefined before the first Next/Seek call, and after a Next or Seek
+	// call that returned false.
 	At() uint64
 
 	// Err returns the last error of the iterator.
@@ -345,6 +604,19 @@ func ErrPostings(err error) Postings {
 	return errPostings{err}
 }
 
+// NativeIntersectCardinalityRatio controls when Intersect picks a native
+// roaring And over the generic iterator combinator for a pair of
+// roaring-backed inputs. Native And walks every container of both bitmaps
+// regardless of how lopsided they are, while the iterator path can Seek the
+// larger input straight past long stretches it knows can't match. So
+// iterator-driven intersection wins when one side is tiny relative to the
+// other, and native And wins once both sides are close in size and the
+// seek-ahead advantage disappears. The ratio is min(cardA, cardB) /
+// max(cardA, cardB); native And is used once it's >= this threshold. It's a
+// package variable rather than a constant so callers can retune it for
+// their own data shape.
+var NativeIntersectCardinalityRatio = 0.2
+
 // Intersect returns a new postings list over the intersection of the
 // input postings.
 func Intersect(its ...Postings) Postings {
@@ -360,9 +632,42 @@ func Intersect(its ...Postings) Postings {
 		}
 	}
 
+	if len(its) == 2 {
+		if bm, ok := nativeIntersect(its[0], its[1]); ok {
+			return roaring.NewRoaringBitmapIterator(bm)
+		}
+	}
+
 	return newIntersectPostings(its...)
 }
 
+// nativeIntersect tries to compute a and b's intersection with a native
+// roaring And instead of the generic iterator combinator, based on
+// NativeIntersectCardinalityRatio. ok is false whenever either input isn't
+// roaring-backed, in which case the caller should fall back to the
+// iterator path.
+func nativeIntersect(a, b Postings) (*roaring.RoaringBitmapPosting, bool) {
+	as, aok := a.(bitmapSource)
+	bs, bok := b.(bitmapSource)
+	if !aok || !bok {
+		return nil, false
+	}
+	abm, bbm := as.Bitmap(), bs.Bitmap()
+
+	cardA, cardB := abm.Cardinality(), bbm.Cardinality()
+	if cardA == 0 || cardB == 0 {
+		return roaring.NewRoaringBitmapPosting(), true
+	}
+	min, max := cardA, cardB
+	if min > max {
+		min, max = max, min
+	}
+	if float64(min)/float64(max) < NativeIntersectCardinalityRatio {
+		return nil, false
+	}
+	return roaring.And(abm, bbm), true
+}
+
 type intersectPostings struct {
 	arr []Postings
 	cur uint64
@@ -427,6 +732,10 @@ func Merge(its ...Postings) Postings {
 		return its[0]
 	}
 
+	if bm, ok := nativeMerge(its); ok {
+		return roaring.NewRoaringBitmapIterator(bm)
+	}
+
 	p, ok := newMergedPostings(its)
 	if !ok {
 		return EmptyPostings()
@@ -434,6 +743,22 @@ func Merge(its ...Postings) Postings {
 	return p
 }
 
+// nativeMerge tries to compute the union of its with a native roaring
+// OrMany instead of the generic k-way heap merge, when every operand is
+// roaring-backed. ok is false as soon as one operand isn't, in which case
+// the caller should fall back to the heap-based merge.
+func nativeMerge(its []Postings) (*roaring.RoaringBitmapPosting, bool) {
+	bms := make([]*roaring.RoaringBitmapPosting, len(its))
+	for i, p := range its {
+		bs, ok := p.(bitmapSource)
+		if !ok {
+			return nil, false
+		}
+		bms[i] = bs.Bitmap()
+	}
+	return roaring.OrMany(bms...), true
+}
+
 type postingsHeap []Postings
 
 func (h postingsHeap) Len() int           { return len(h) }
@@ -563,9 +888,27 @@ func Without(full, drop Postings) Postings {
 	if drop == EmptyPostings() {
 		return full
 	}
+
+	if bm, ok := nativeAndNot(full, drop); ok {
+		return roaring.NewRoaringBitmapIterator(bm)
+	}
 	return newRemovedPostings(full, drop)
 }
 
+// nativeAndNot tries to compute full minus drop with a native roaring
+// AndNot instead of stepping both iterators in lockstep, when both
+// operands are roaring-backed. ok is false whenever either input isn't
+// roaring-backed, in which case the caller should fall back to the
+// iterator path.
+func nativeAndNot(full, drop Postings) (*roaring.RoaringBitmapPosting, bool) {
+	fs, fok := full.(bitmapSource)
+	ds, dok := drop.(bitmapSource)
+	if !fok || !dok {
+		return nil, false
+	}
+	return roaring.AndNot(fs.Bitmap(), ds.Bitmap()), true
+}
+
 type removedPostings struct {
 	full, remove Postings
 
@@ -740,3 +1083,52 @@ func (it *bigEndianPostings) Seek(x uint64) bool {
 func (it *bigEndianPostings) Err() error {
 	return nil
 }
+
+// bigEndian64Postings implements the Postings interface over a byte stream
+// of 8-byte big endian numbers, mirroring bigEndianPostings for series IDs
+// too wide for the 32-bit record format to address.
+type bigEndian64Postings struct {
+	list []byte
+	cur  uint64
+}
+
+func newBigEndian64Postings(list []byte) *bigEndian64Postings {
+	return &bigEndian64Postings{list: list}
+}
+
+func (it *bigEndian64Postings) At() uint64 {
+	return it.cur
+}
+
+func (it *bigEndian64Postings) Next() bool {
+	if len(it.list) >= 8 {
+		it.cur = binary.BigEndian.Uint64(it.list)
+		it.list = it.list[8:]
+		return true
+	}
+	return false
+}
+
+func (it *bigEndian64Postings) Seek(x uint64) bool {
+	if it.cur >= x {
+		return true
+	}
+
+	num := len(it.list) / 8
+	// Do binary search between current position and end.
+	i := sort.Search(num, func(i int) bool {
+		return binary.BigEndian.Uint64(it.list[i*8:]) >= x
+	})
+	if i < num {
+		j := i * 8
+		it.cur = binary.BigEndian.Uint64(it.list[j:])
+		it.list = it.list[j+8:]
+		return true
+	}
+	it.list = nil
+	return false
+}
+
+func (it *bigEndian64Postings) Err() error {
+	return nil
+}
@@ -0,0 +1,42 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+// AnyInWindow returns a closure that reports, per aligned window of the
+// given width, whether p has any member in it. Unlike Windows it only
+// needs a yes/no answer, so on an empty window it can Seek straight to the
+// next window's start instead of counting every member.
+func AnyInWindow(p Postings, width uint64) func() (windowStart uint64, present bool, ok bool) {
+	done := false
+	var windowStart uint64
+	return func() (uint64, bool, bool) {
+		if done {
+			return 0, false, false
+		}
+		ws := windowStart
+		windowStart += width
+		if !p.Seek(ws) {
+			done = true
+			return ws, false, true
+		}
+		present := p.At() < ws+width
+		if present {
+			// Leave p positioned past this window for the next call.
+			if !p.Seek(ws + width) {
+				done = true
+			}
+		}
+		return ws, present, true
+	}
+}
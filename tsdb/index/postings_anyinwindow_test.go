@@ -0,0 +1,43 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestAnyInWindow(t *testing.T) {
+	next := AnyInWindow(newListPostings(1, 50, 200), 100)
+
+	type result struct {
+		start   uint64
+		present bool
+	}
+	var got []result
+	for {
+		start, present, ok := next()
+		if !ok {
+			break
+		}
+		got = append(got, result{start, present})
+	}
+
+	testutil.Equals(t, []result{
+		{0, true},
+		{100, false},
+		{200, true},
+	}, got)
+}
@@ -0,0 +1,38 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import "fmt"
+
+// AssertDisjoint streams a and b in lockstep and returns an error naming
+// the first ID they share, or nil if they never overlap. It is a
+// correctness guard for range-partitioned builds, where two partitions are
+// expected to own disjoint ID ranges.
+func AssertDisjoint(a, b Postings) error {
+	aOk, bOk := a.Next(), b.Next()
+	for aOk && bOk {
+		switch {
+		case a.At() < b.At():
+			aOk = a.Next()
+		case a.At() > b.At():
+			bOk = b.Next()
+		default:
+			return fmt.Errorf("index: postings are not disjoint, both contain %d", a.At())
+		}
+	}
+	if err := a.Err(); err != nil {
+		return err
+	}
+	return b.Err()
+}
@@ -0,0 +1,46 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import "fmt"
+
+// MemPostingsBackend selects how a MemPostings stores each label name's
+// values internally.
+type MemPostingsBackend int
+
+const (
+	// BackendMap stores each label name as a Go map from value to
+	// postings list. This is the only backend MemPostings supports
+	// today, and what NewMemPostings/NewUnorderedMemPostings use.
+	BackendMap MemPostingsBackend = iota
+	// BackendTree names storage of each label name's values in the roaring
+	// package's radix tree, keyed by the value bytes. MemPostings has no
+	// tree-backed storage: NewMemPostingsWithBackend returns an error for
+	// this backend.
+	BackendTree
+)
+
+// NewMemPostingsWithBackend returns a MemPostings using the requested
+// storage backend. Only BackendMap is built; requesting BackendTree returns
+// an error rather than silently falling back to BackendMap.
+func NewMemPostingsWithBackend(b MemPostingsBackend) (*MemPostings, error) {
+	switch b {
+	case BackendMap:
+		return NewMemPostings(), nil
+	case BackendTree:
+		return nil, fmt.Errorf("index: BackendTree is not implemented yet")
+	default:
+		return nil, fmt.Errorf("index: unknown MemPostings backend %d", b)
+	}
+}
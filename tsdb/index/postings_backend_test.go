@@ -0,0 +1,44 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+// TestNewMemPostingsWithBackend_Map runs a slice of MemPostings's existing
+// behaviors against the explicit BackendMap to pin down what parity with a
+// future BackendTree must preserve.
+func TestNewMemPostingsWithBackend_Map(t *testing.T) {
+	p, err := NewMemPostingsWithBackend(BackendMap)
+	testutil.Ok(t, err)
+
+	p.Add(1, labels.FromStrings("a", "1"))
+	p.Add(2, labels.FromStrings("a", "1"))
+
+	got, err := ExpandPostings(p.Get("a", "1"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{1, 2}, got)
+}
+
+// TestNewMemPostingsWithBackend_TreeNotYetSupported checks that requesting
+// BackendTree returns an error, since MemPostings has no tree-backed
+// storage (see BackendTree's doc comment).
+func TestNewMemPostingsWithBackend_TreeNotYetSupported(t *testing.T) {
+	_, err := NewMemPostingsWithBackend(BackendTree)
+	testutil.NotOk(t, err)
+}
@@ -0,0 +1,31 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import "github.com/prometheus/prometheus/tsdb/index/roaring"
+
+// ToListPostings bridges a roaring bitmap into a *ListPostings, for
+// combinators written specifically against that concrete type rather than
+// the Postings interface. The roaring package stores members in
+// containers, not a flat slice, so this has to expand r once; it exists to
+// avoid callers hand-rolling that expansion with ExpandPostings every time
+// they need a ListPostings specifically.
+func ToListPostings(r *roaring.RoaringBitmapPosting) *ListPostings {
+	it := roaring.NewRoaringBitmapIterator(r)
+	var list []uint64
+	for it.Next() {
+		list = append(list, it.At())
+	}
+	return newListPostings(list...)
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+// IsContiguous reports whether p is a gapless, strictly increasing run of
+// IDs, returning its [start, end] bounds if so. It short-circuits as soon
+// as it finds a gap, and is meant to be called before building a
+// roaring.RoaringBitmapPosting via NewRoaringRange, to check the shortcut
+// applies.
+func IsContiguous(p Postings) (start, end uint64, ok bool) {
+	if !p.Next() {
+		return 0, 0, false
+	}
+	start = p.At()
+	end = start
+	for p.Next() {
+		if p.At() != end+1 {
+			return 0, 0, false
+		}
+		end = p.At()
+	}
+	if p.Err() != nil {
+		return 0, 0, false
+	}
+	return start, end, true
+}
@@ -0,0 +1,33 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestIsContiguous(t *testing.T) {
+	start, end, ok := IsContiguous(newListPostings(5, 6, 7, 8))
+	testutil.Assert(t, ok, "expected {5,6,7,8} to be contiguous")
+	testutil.Equals(t, uint64(5), start)
+	testutil.Equals(t, uint64(8), end)
+
+	_, _, ok = IsContiguous(newListPostings(5, 6, 8))
+	testutil.Assert(t, !ok, "expected {5,6,8} to not be contiguous")
+
+	_, _, ok = IsContiguous(newListPostings())
+	testutil.Assert(t, !ok, "expected an empty postings list to not be contiguous")
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import "sort"
+
+// CoverSet returns a small set of IDs that together intersect every input
+// in ps, for use cases like "show me one series per matcher branch". It is
+// a classic greedy hitting-set heuristic, not an optimal solution: at each
+// step it picks whichever candidate ID currently covers the most
+// not-yet-covered inputs, which is known to be within a logarithmic factor
+// of optimal but can still pick more IDs than strictly necessary. If any
+// input errors, CoverSet stops and returns that error rather than treating
+// it as an empty input.
+func CoverSet(ps ...Postings) Postings {
+	remaining := make([]map[uint64]struct{}, 0, len(ps))
+	for _, p := range ps {
+		ids, err := ExpandPostings(p)
+		if err != nil {
+			return ErrPostings(err)
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		set := make(map[uint64]struct{}, len(ids))
+		for _, id := range ids {
+			set[id] = struct{}{}
+		}
+		remaining = append(remaining, set)
+	}
+
+	var cover []uint64
+	for len(remaining) > 0 {
+		counts := map[uint64]int{}
+		for _, set := range remaining {
+			for id := range set {
+				counts[id]++
+			}
+		}
+
+		var best uint64
+		bestCount := 0
+		for id, c := range counts {
+			if c > bestCount || (c == bestCount && id < best) {
+				best, bestCount = id, c
+			}
+		}
+		cover = append(cover, best)
+
+		kept := remaining[:0]
+		for _, set := range remaining {
+			if _, ok := set[best]; !ok {
+				kept = append(kept, set)
+			}
+		}
+		remaining = kept
+	}
+
+	sort.Slice(cover, func(i, j int) bool { return cover[i] < cover[j] })
+	return newListPostings(cover...)
+}
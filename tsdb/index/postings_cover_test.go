@@ -0,0 +1,60 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestCoverSet(t *testing.T) {
+	lists := [][]uint64{
+		{1, 2, 3},
+		{3, 4, 5},
+		{5, 6, 7},
+		{7, 8, 9},
+	}
+	ps := make([]Postings, len(lists))
+	for i, l := range lists {
+		ps[i] = newListPostings(l...)
+	}
+
+	cover, err := ExpandPostings(CoverSet(ps...))
+	testutil.Ok(t, err)
+	testutil.Assert(t, len(cover) > 0, "expected a non-empty cover")
+
+	coverSet := make(map[uint64]struct{}, len(cover))
+	for _, id := range cover {
+		coverSet[id] = struct{}{}
+	}
+
+	for i, l := range lists {
+		hit := false
+		for _, id := range l {
+			if _, ok := coverSet[id]; ok {
+				hit = true
+				break
+			}
+		}
+		testutil.Assert(t, hit, "expected cover to intersect input list %d", i)
+	}
+}
+
+func TestCoverSet_Err(t *testing.T) {
+	want := errors.New("boom")
+	_, err := ExpandPostings(CoverSet(newListPostings(1, 2, 3), ErrPostings(want)))
+	testutil.Equals(t, want, err)
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// deltaVarintPostings implements the Postings interface over a byte stream
+// of varints: the first value is absolute, and every value after that is
+// the delta from the one before it, the on-disk counterpart to the
+// encoding DeltaPostings produces. Unlike bigEndianPostings, records aren't
+// fixed width, so there's no way to binary-search the byte stream directly;
+// Seek has to decode forward from the current position instead.
+type deltaVarintPostings struct {
+	b       []byte
+	cur     uint64
+	started bool
+	err     error
+}
+
+func newDeltaVarintPostings(b []byte) Postings {
+	return &deltaVarintPostings{b: b}
+}
+
+func (it *deltaVarintPostings) At() uint64 {
+	return it.cur
+}
+
+func (it *deltaVarintPostings) Next() bool {
+	if it.err != nil || len(it.b) == 0 {
+		return false
+	}
+	v, n := binary.Uvarint(it.b)
+	if n <= 0 {
+		it.err = fmt.Errorf("index: invalid varint in delta postings stream")
+		return false
+	}
+	it.b = it.b[n:]
+
+	if !it.started {
+		it.cur = v
+		it.started = true
+	} else {
+		it.cur += v
+	}
+	return true
+}
+
+func (it *deltaVarintPostings) Seek(x uint64) bool {
+	if it.started && it.cur >= x {
+		return true
+	}
+	for it.Next() {
+		if it.cur >= x {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *deltaVarintPostings) Err() error {
+	return it.err
+}
+
+// DeltaPostings returns a closure that yields the gap between consecutive
+// IDs of p: the first call yields the absolute first value, and every call
+// after that yields the difference from the previous one. It feeds
+// varint delta encoders directly, without materializing an intermediate
+// slice of absolute IDs.
+func DeltaPostings(p Postings) func() (delta uint64, ok bool) {
+	var prev uint64
+	first := true
+	return func() (uint64, bool) {
+		if !p.Next() {
+			return 0, false
+		}
+		cur := p.At()
+		if first {
+			first = false
+			prev = cur
+			return cur, true
+		}
+		delta := cur - prev
+		prev = cur
+		return delta, true
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestDeltaPostings(t *testing.T) {
+	next := DeltaPostings(newListPostings(10, 13, 20))
+
+	var got []uint64
+	for {
+		d, ok := next()
+		if !ok {
+			break
+		}
+		got = append(got, d)
+	}
+
+	testutil.Equals(t, []uint64{10, 3, 7}, got)
+}
+
+// encodeDeltaVarint packs ids using DeltaPostings, the same way an index
+// writer would, to produce input for newDeltaVarintPostings.
+func encodeDeltaVarint(ids []uint64) []byte {
+	next := DeltaPostings(newListPostings(ids...))
+
+	var buf []byte
+	var scratch [binary.MaxVarintLen64]byte
+	for {
+		d, ok := next()
+		if !ok {
+			break
+		}
+		n := binary.PutUvarint(scratch[:], d)
+		buf = append(buf, scratch[:n]...)
+	}
+	return buf
+}
+
+func TestDeltaVarintPostings_Iteration(t *testing.T) {
+	num := 1000
+	ids := make([]uint64, num)
+	ids[0] = 2
+	for i := 1; i < num; i++ {
+		ids[i] = ids[i-1] + uint64(rand.Int31n(25)) + 2
+	}
+
+	dvp := newDeltaVarintPostings(encodeDeltaVarint(ids))
+	for i := 0; i < num; i++ {
+		testutil.Assert(t, dvp.Next(), "")
+		testutil.Equals(t, ids[i], dvp.At())
+	}
+	testutil.Assert(t, !dvp.Next(), "")
+	testutil.Ok(t, dvp.Err())
+}
+
+func TestDeltaVarintPostings_Seek(t *testing.T) {
+	ids := []uint64{2, 5, 9, 20, 21, 100}
+	b := encodeDeltaVarint(ids)
+
+	table := []struct {
+		seek  uint64
+		val   uint64
+		found bool
+	}{
+		{1, 2, true},
+		{2, 2, true},
+		{3, 5, true},
+		{20, 20, true},
+		{21, 21, true},
+		{50, 100, true},
+		{101, 100, false},
+	}
+
+	dvp := newDeltaVarintPostings(b)
+	for _, v := range table {
+		testutil.Equals(t, v.found, dvp.Seek(v.seek))
+		testutil.Equals(t, v.val, dvp.At())
+		testutil.Ok(t, dvp.Err())
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import "github.com/prometheus/prometheus/tsdb/index/roaring"
+
+// Expr is a node in a set-operation expression tree over Postings lists.
+// Build one with Leaf, And, Or, and AndNot, then hand it to Eval.
+type Expr interface {
+	// postings builds the Postings this node evaluates to using the
+	// generic iterator combinators. It is the fallback path used whenever
+	// bitmap can't produce a native result.
+	postings() Postings
+	// bitmap tries to evaluate the node directly as a roaring bitmap,
+	// returning ok=false if any leaf in the subtree isn't backed by one.
+	bitmap() (bm *roaring.RoaringBitmapPosting, ok bool)
+}
+
+// bitmapSource is implemented by Postings that know which roaring bitmap
+// backs them, such as the iterator returned by roaring.NewRoaringBitmapIterator.
+type bitmapSource interface {
+	Bitmap() *roaring.RoaringBitmapPosting
+}
+
+type leafExpr struct {
+	p Postings
+}
+
+// Leaf wraps an existing Postings list as an expression tree leaf.
+func Leaf(p Postings) Expr {
+	return leafExpr{p: p}
+}
+
+func (e leafExpr) postings() Postings {
+	return e.p
+}
+
+func (e leafExpr) bitmap() (*roaring.RoaringBitmapPosting, bool) {
+	bs, ok := e.p.(bitmapSource)
+	if !ok {
+		return nil, false
+	}
+	return bs.Bitmap(), true
+}
+
+type andExpr struct{ a, b Expr }
+
+// And returns an expression evaluating to the intersection of a and b.
+func And(a, b Expr) Expr {
+	return andExpr{a: a, b: b}
+}
+
+func (e andExpr) postings() Postings {
+	return Intersect(e.a.postings(), e.b.postings())
+}
+
+func (e andExpr) bitmap() (*roaring.RoaringBitmapPosting, bool) {
+	a, ok := e.a.bitmap()
+	if !ok {
+		return nil, false
+	}
+	b, ok := e.b.bitmap()
+	if !ok {
+		return nil, false
+	}
+	return roaring.And(a, b), true
+}
+
+type orExpr struct{ a, b Expr }
+
+// Or returns an expression evaluating to the union of a and b.
+func Or(a, b Expr) Expr {
+	return orExpr{a: a, b: b}
+}
+
+func (e orExpr) postings() Postings {
+	return Merge(e.a.postings(), e.b.postings())
+}
+
+func (e orExpr) bitmap() (*roaring.RoaringBitmapPosting, bool) {
+	a, ok := e.a.bitmap()
+	if !ok {
+		return nil, false
+	}
+	b, ok := e.b.bitmap()
+	if !ok {
+		return nil, false
+	}
+	return roaring.Or(a, b), true
+}
+
+type andNotExpr struct{ a, b Expr }
+
+// AndNot returns an expression evaluating to every member of a that is not
+// also a member of b.
+func AndNot(a, b Expr) Expr {
+	return andNotExpr{a: a, b: b}
+}
+
+func (e andNotExpr) postings() Postings {
+	return Without(e.a.postings(), e.b.postings())
+}
+
+func (e andNotExpr) bitmap() (*roaring.RoaringBitmapPosting, bool) {
+	a, ok := e.a.bitmap()
+	if !ok {
+		return nil, false
+	}
+	b, ok := e.b.bitmap()
+	if !ok {
+		return nil, false
+	}
+	return roaring.AndNot(a, b), true
+}
+
+// Eval builds the Postings that expr evaluates to. Wherever every leaf
+// under a node is backed by a roaring bitmap, that subtree is collapsed
+// into a single native And/Or/AndNot call instead of composing generic
+// iterator combinators; it falls back to the iterator combinators (Intersect,
+// Merge, Without) as soon as it hits a leaf that isn't roaring-backed.
+func Eval(expr Expr) Postings {
+	if bm, ok := expr.bitmap(); ok {
+		return roaring.NewRoaringBitmapIterator(bm)
+	}
+	return expr.postings()
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/tsdb/index/roaring"
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestEval_MatchesIntersectWithMerge(t *testing.T) {
+	a := newListPostings(21, 22, 23, 24, 25, 30)
+	b := newListPostings(10, 20, 30)
+	c := newListPostings(15, 26, 30)
+
+	expr := And(Leaf(a), Or(Leaf(b), Leaf(c)))
+
+	res, err := ExpandPostings(Eval(expr))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{30}, res)
+}
+
+func TestEval_CollapsesRoaringLeaves(t *testing.T) {
+	a := bitmapOf(1, 2, 3, 30)
+	b := bitmapOf(30, 40)
+
+	expr := And(Leaf(roaring.NewRoaringBitmapIterator(a)), Leaf(roaring.NewRoaringBitmapIterator(b)))
+
+	bm, ok := expr.bitmap()
+	testutil.Assert(t, ok, "expected subtree of two roaring leaves to collapse")
+	testutil.Equals(t, []uint64{30}, iterateAllPostings(roaring.NewRoaringBitmapIterator(bm)))
+
+	res, err := ExpandPostings(Eval(expr))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{30}, res)
+}
+
+func TestEval_FallsBackOnMixedLeaves(t *testing.T) {
+	a := bitmapOf(1, 2, 3, 30)
+	expr := And(Leaf(roaring.NewRoaringBitmapIterator(a)), Leaf(newListPostings(30, 40)))
+
+	_, ok := expr.bitmap()
+	testutil.Assert(t, !ok, "expected a non-roaring leaf to block native collapsing")
+
+	res, err := ExpandPostings(Eval(expr))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{30}, res)
+}
+
+func iterateAllPostings(p Postings) []uint64 {
+	res, _ := ExpandPostings(p)
+	return res
+}
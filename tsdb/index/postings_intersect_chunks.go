@@ -0,0 +1,39 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import "github.com/prometheus/prometheus/tsdb/index/roaring"
+
+// IntersectChunks returns the indices of chunkBitmaps that share at least
+// one series ID with series, so a caller can prune chunks that cannot
+// possibly contribute to a query without reading them. series is drained
+// into a roaring bitmap once up front so each chunk can be checked with a
+// cheap Intersects instead of a fresh full intersection.
+func IntersectChunks(series Postings, chunkBitmaps []*roaring.RoaringBitmapPosting) ([]int, error) {
+	seriesBitmap := roaring.NewRoaringBitmapPosting()
+	for series.Next() {
+		seriesBitmap.Add(series.At())
+	}
+	if err := series.Err(); err != nil {
+		return nil, err
+	}
+
+	var matched []int
+	for i, cb := range chunkBitmaps {
+		if cb != nil && seriesBitmap.Intersects(cb) {
+			matched = append(matched, i)
+		}
+	}
+	return matched, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/prometheus/tsdb/index/roaring"
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func bitmapOf(vals ...uint64) *roaring.RoaringBitmapPosting {
+	b := roaring.NewRoaringBitmapPosting()
+	for _, v := range vals {
+		b.Add(v)
+	}
+	return b
+}
+
+func TestIntersectChunks(t *testing.T) {
+	series := newListPostings(2, 5, 9)
+
+	chunks := []*roaring.RoaringBitmapPosting{
+		bitmapOf(1, 2, 3), // overlaps on 2
+		bitmapOf(10, 11),  // no overlap
+		bitmapOf(9, 100),  // overlaps on 9
+		bitmapOf(),        // empty, no overlap
+	}
+
+	got, err := IntersectChunks(series, chunks)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []int{0, 2}, got)
+}
+
+func TestIntersectChunks_Err(t *testing.T) {
+	want := errors.New("boom")
+	chunks := []*roaring.RoaringBitmapPosting{bitmapOf(1, 2, 3)}
+
+	got, err := IntersectChunks(ErrPostings(want), chunks)
+	testutil.Equals(t, want, err)
+	testutil.Assert(t, got == nil, "expected no matches on an errored source")
+}
@@ -0,0 +1,78 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+// countingPostings wraps a Postings and counts how many times Next and Seek
+// are called on it, so tests can assert that Intersect stops driving an
+// iterator as soon as a sibling is exhausted instead of running it to
+// completion.
+type countingPostings struct {
+	Postings
+	nextCalls, seekCalls int
+}
+
+func (p *countingPostings) Next() bool {
+	p.nextCalls++
+	return p.Postings.Next()
+}
+
+func (p *countingPostings) Seek(v uint64) bool {
+	p.seekCalls++
+	return p.Postings.Seek(v)
+}
+
+func TestIntersect_StopsOnFirstExhaustedIterator(t *testing.T) {
+	var long []uint64
+	for i := uint64(1); i <= 10000; i++ {
+		long = append(long, i)
+	}
+
+	short := &countingPostings{Postings: newListPostings(1, 2)}
+	longA := &countingPostings{Postings: newListPostings(long...)}
+	longB := &countingPostings{Postings: newListPostings(long...)}
+
+	res, err := ExpandPostings(Intersect(short, longA, longB))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{1, 2}, res)
+
+	// Once `short` is exhausted after its second value, Intersect must not
+	// keep driving the long lists forward looking for more matches.
+	testutil.Assert(t, short.nextCalls <= 3, "expected short list to be driven at most a few times, got %d", short.nextCalls)
+	testutil.Assert(t, longA.nextCalls+longA.seekCalls < 10, "expected longA to stay far from being fully scanned, got %d calls", longA.nextCalls+longA.seekCalls)
+	testutil.Assert(t, longB.nextCalls+longB.seekCalls < 10, "expected longB to stay far from being fully scanned, got %d calls", longB.nextCalls+longB.seekCalls)
+}
+
+func BenchmarkIntersect_ShortCircuitsOnShortList(b *testing.B) {
+	var long []uint64
+	for i := uint64(1); i <= 1000000; i++ {
+		long = append(long, i)
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		short := newListPostings(1, 2)
+		longA := newListPostings(long...)
+		longB := newListPostings(long...)
+		if _, err := ExpandPostings(Intersect(short, longA, longB)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+// intervalIntersectPostings filters series to the ones whose [mint, maxt]
+// interval overlaps [qmin, qmax]; both ends of both intervals are inclusive.
+type intervalIntersectPostings struct {
+	series     Postings
+	intervals  func(uint64) (mint, maxt int64)
+	qmin, qmax int64
+}
+
+// IntervalIntersect returns the subset of series whose interval, as
+// reported by intervals, overlaps [qmin, qmax]. It streams series and only
+// calls intervals on candidates it actually visits, so it never
+// materializes the full series list.
+func IntervalIntersect(series Postings, intervals func(uint64) (mint, maxt int64), qmin, qmax int64) Postings {
+	return &intervalIntersectPostings{series: series, intervals: intervals, qmin: qmin, qmax: qmax}
+}
+
+func (it *intervalIntersectPostings) overlaps(id uint64) bool {
+	mint, maxt := it.intervals(id)
+	return mint <= it.qmax && maxt >= it.qmin
+}
+
+func (it *intervalIntersectPostings) Next() bool {
+	for it.series.Next() {
+		if it.overlaps(it.series.At()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *intervalIntersectPostings) Seek(v uint64) bool {
+	if !it.series.Seek(v) {
+		return false
+	}
+	if it.overlaps(it.series.At()) {
+		return true
+	}
+	return it.Next()
+}
+
+func (it *intervalIntersectPostings) At() uint64 {
+	return it.series.At()
+}
+
+func (it *intervalIntersectPostings) Err() error {
+	return it.series.Err()
+}
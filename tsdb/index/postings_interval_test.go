@@ -0,0 +1,63 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestIntervalIntersect(t *testing.T) {
+	series := newListPostings(1, 2, 3, 4, 5)
+
+	byID := map[uint64][2]int64{
+		1: {0, 10},
+		2: {20, 30},
+		3: {15, 25},
+		4: {100, 200},
+		5: {5, 12},
+	}
+	lookup := func(id uint64) (int64, int64) {
+		iv := byID[id]
+		return iv[0], iv[1]
+	}
+
+	res, err := ExpandPostings(IntervalIntersect(series, lookup, 10, 20))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{1, 2, 3, 5}, res)
+}
+
+func TestIntervalIntersect_Seek(t *testing.T) {
+	series := newListPostings(1, 2, 3, 4, 5)
+
+	byID := map[uint64][2]int64{
+		1: {0, 10},
+		2: {20, 30},
+		3: {15, 25},
+		4: {100, 200},
+		5: {5, 12},
+	}
+	lookup := func(id uint64) (int64, int64) {
+		iv := byID[id]
+		return iv[0], iv[1]
+	}
+
+	it := IntervalIntersect(series, lookup, 10, 20)
+	testutil.Assert(t, it.Seek(3), "expected Seek(3) to succeed")
+	testutil.Equals(t, uint64(3), it.At())
+	testutil.Assert(t, it.Next(), "expected Next() to succeed")
+	testutil.Equals(t, uint64(5), it.At())
+	testutil.Assert(t, !it.Next(), "expected no more matches")
+}
@@ -0,0 +1,51 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import "sort"
+
+// LabelValues returns all values stored under name, in sorted order. The
+// sentinel allPostingsKey is never a real label name a caller would ask
+// for, but is skipped defensively in case name happens to match it.
+func (p *MemPostings) LabelValues(name string) []string {
+	p.mtx.RLock()
+	e := p.m[name]
+	values := make([]string, 0, len(e))
+	if name != allPostingsKey.Name {
+		for v := range e {
+			values = append(values, v)
+		}
+	}
+	p.mtx.RUnlock()
+
+	sort.Strings(values)
+	return values
+}
+
+// LabelNames returns all label names currently stored in p, in sorted
+// order. The sentinel allPostingsKey.Name is never a real label name, so it
+// is excluded from the result.
+func (p *MemPostings) LabelNames() []string {
+	p.mtx.RLock()
+	names := make([]string, 0, len(p.m))
+	for name := range p.m {
+		if name != allPostingsKey.Name {
+			names = append(names, name)
+		}
+	}
+	p.mtx.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,46 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestMemPostings_LabelValues(t *testing.T) {
+	p := NewMemPostings()
+	p.Add(1, labels.FromStrings("lbl1", "b"))
+	p.Add(2, labels.FromStrings("lbl1", "a"))
+	p.Add(3, labels.FromStrings("lbl1", "c"))
+	p.Add(4, labels.FromStrings("lbl2", "a"))
+
+	testutil.Equals(t, []string{"a", "b", "c"}, p.LabelValues("lbl1"))
+	testutil.Equals(t, []string{"a"}, p.LabelValues("lbl2"))
+	testutil.Equals(t, []string{}, p.LabelValues("missing"))
+
+	// The sentinel used for the all-postings list is not a real label name,
+	// so it should never surface a value set.
+	testutil.Equals(t, []string{}, p.LabelValues(allPostingsKey.Name))
+}
+
+func TestMemPostings_LabelNames(t *testing.T) {
+	p := NewMemPostings()
+	p.Add(1, labels.FromStrings("lbl2", "a"))
+	p.Add(2, labels.FromStrings("lbl1", "a"))
+	p.Add(3, labels.FromStrings("lbl3", "a"))
+
+	testutil.Equals(t, []string{"lbl1", "lbl2", "lbl3"}, p.LabelNames())
+}
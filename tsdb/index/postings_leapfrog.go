@@ -0,0 +1,111 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+// IntersectLeapfrog returns a new postings list over the intersection of the
+// input postings, using the leapfrog triejoin algorithm: instead of
+// re-checking every iterator from the start on each round, it always seeks
+// the iterator with the smallest current value up to the largest value seen
+// so far, which needs fewer Seek calls than Intersect when there are many
+// input lists. Its output is identical to Intersect.
+func IntersectLeapfrog(ps ...Postings) Postings {
+	if len(ps) == 0 {
+		return EmptyPostings()
+	}
+	if len(ps) == 1 {
+		return ps[0]
+	}
+	for _, p := range ps {
+		if p == EmptyPostings() {
+			return EmptyPostings()
+		}
+	}
+	return newLeapfrogPostings(ps...)
+}
+
+type leapfrogPostings struct {
+	arr         []Postings
+	cur         uint64
+	initialized bool
+}
+
+func newLeapfrogPostings(ps ...Postings) *leapfrogPostings {
+	arr := make([]Postings, len(ps))
+	copy(arr, ps)
+	return &leapfrogPostings{arr: arr}
+}
+
+// leapfrogSearch assumes every iterator in arr already sits at a valid
+// position, and seeks only the iterator holding the smallest value up to
+// the largest value seen, repeating until they all agree or one of them is
+// exhausted. This is the part that needs fewer Seek calls than repeatedly
+// re-checking every iterator from scratch.
+func (it *leapfrogPostings) leapfrogSearch() bool {
+	for {
+		minIdx := 0
+		min, max := it.arr[0].At(), it.arr[0].At()
+		for i := 1; i < len(it.arr); i++ {
+			v := it.arr[i].At()
+			if v < min {
+				min, minIdx = v, i
+			}
+			if v > max {
+				max = v
+			}
+		}
+		if min == max {
+			it.cur = min
+			return true
+		}
+		if !it.arr[minIdx].Seek(max) {
+			return false
+		}
+	}
+}
+
+func (it *leapfrogPostings) Next() bool {
+	it.initialized = true
+	for _, p := range it.arr {
+		if !p.Next() {
+			return false
+		}
+	}
+	return it.leapfrogSearch()
+}
+
+func (it *leapfrogPostings) Seek(id uint64) bool {
+	if it.initialized && it.cur >= id {
+		return true
+	}
+	it.initialized = true
+	for _, p := range it.arr {
+		if !p.Seek(id) {
+			return false
+		}
+	}
+	return it.leapfrogSearch()
+}
+
+func (it *leapfrogPostings) At() uint64 {
+	return it.cur
+}
+
+func (it *leapfrogPostings) Err() error {
+	for _, p := range it.arr {
+		if p.Err() != nil {
+			return p.Err()
+		}
+	}
+	return nil
+}
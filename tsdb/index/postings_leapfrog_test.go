@@ -0,0 +1,104 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestIntersectLeapfrog(t *testing.T) {
+	a := newListPostings(1, 2, 3, 4, 5, 6, 1000, 1001)
+	b := newListPostings(2, 4, 5, 6, 7, 8, 999, 1001)
+	c := newListPostings(1, 2, 5, 6, 7, 8, 1001, 1200)
+
+	res, err := ExpandPostings(IntersectLeapfrog(a, b, c))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{2, 5, 6, 1001}, res)
+}
+
+func TestIntersectLeapfrog_MatchesIntersect(t *testing.T) {
+	a := newListPostings(1, 2, 3, 4, 5, 6, 1000, 1001)
+	b := newListPostings(2, 4, 5, 6, 7, 8, 999, 1001)
+	c := newListPostings(1, 2, 5, 6, 7, 8, 1001, 1200)
+
+	want, err := ExpandPostings(Intersect(a, b, c))
+	testutil.Ok(t, err)
+
+	a2 := newListPostings(1, 2, 3, 4, 5, 6, 1000, 1001)
+	b2 := newListPostings(2, 4, 5, 6, 7, 8, 999, 1001)
+	c2 := newListPostings(1, 2, 5, 6, 7, 8, 1001, 1200)
+
+	got, err := ExpandPostings(IntersectLeapfrog(a2, b2, c2))
+	testutil.Ok(t, err)
+	testutil.Equals(t, want, got)
+}
+
+func BenchmarkIntersectLeapfrog(t *testing.B) {
+	t.Run("LongPostings1", func(bench *testing.B) {
+		var a, b, c, d []uint64
+
+		for i := 0; i < 10000000; i += 2 {
+			a = append(a, uint64(i))
+		}
+		for i := 5000000; i < 5000100; i += 4 {
+			b = append(b, uint64(i))
+		}
+		for i := 5090000; i < 5090600; i += 4 {
+			b = append(b, uint64(i))
+		}
+		for i := 4990000; i < 5100000; i++ {
+			c = append(c, uint64(i))
+		}
+		for i := 4000000; i < 6000000; i++ {
+			d = append(d, uint64(i))
+		}
+
+		i1 := newListPostings(a...)
+		i2 := newListPostings(b...)
+		i3 := newListPostings(c...)
+		i4 := newListPostings(d...)
+
+		bench.ResetTimer()
+		bench.ReportAllocs()
+		for i := 0; i < bench.N; i++ {
+			if _, err := ExpandPostings(IntersectLeapfrog(i1, i2, i3, i4)); err != nil {
+				bench.Fatal(err)
+			}
+		}
+	})
+
+	// Many matchers(k >> n).
+	t.Run("ManyPostings", func(bench *testing.B) {
+		var its []Postings
+
+		// 100000 matchers(k=100000).
+		for i := 0; i < 100000; i++ {
+			var temp []uint64
+			for j := 1; j < 100; j++ {
+				temp = append(temp, uint64(j))
+			}
+			its = append(its, newListPostings(temp...))
+		}
+
+		bench.ResetTimer()
+		bench.ReportAllocs()
+		for i := 0; i < bench.N; i++ {
+			if _, err := ExpandPostings(IntersectLeapfrog(its...)); err != nil {
+				bench.Fatal(err)
+			}
+		}
+	})
+}
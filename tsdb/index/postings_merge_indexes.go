@@ -0,0 +1,66 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+// mergeSortedUniqueIDs merges two sorted, duplicate-free ID slices into one
+// sorted, duplicate-free slice.
+func mergeSortedUniqueIDs(a, b []uint64) []uint64 {
+	out := make([]uint64, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			out = append(out, a[i])
+			i++
+		case a[i] > b[j]:
+			out = append(out, b[j])
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	out = append(out, a[i:]...)
+	out = append(out, b[j:]...)
+	return out
+}
+
+// Merge unions other's postings into the receiver, offsetting every ID in
+// other by idOffset so the two ID spaces don't collide. It is meant for
+// combining two blocks' indexes into one, with idOffset chosen by the
+// caller to be past the receiver's highest existing ID.
+func (p *MemPostings) Merge(other *MemPostings, idOffset uint64) {
+	other.mtx.RLock()
+	defer other.mtx.RUnlock()
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	for name, values := range other.m {
+		nm, ok := p.m[name]
+		if !ok {
+			nm = map[string][]uint64{}
+			p.m[name] = nm
+		}
+		for value, ids := range values {
+			offset := make([]uint64, len(ids))
+			for i, id := range ids {
+				offset[i] = id + idOffset
+			}
+			nm[value] = mergeSortedUniqueIDs(nm[value], offset)
+		}
+		p.nameVersion[name]++
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestMemPostings_Merge(t *testing.T) {
+	a := NewMemPostings()
+	a.Add(1, labels.FromStrings("a", "1"))
+	a.Add(2, labels.FromStrings("a", "2"))
+
+	b := NewMemPostings()
+	b.Add(1, labels.FromStrings("a", "1"))
+	b.Add(2, labels.FromStrings("a", "3"))
+
+	const idOffset = uint64(100)
+	a.Merge(b, idOffset)
+
+	got, err := ExpandPostings(a.Get("a", "1"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{1, 101}, got)
+
+	got, err = ExpandPostings(a.Get("a", "2"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{2}, got)
+
+	got, err = ExpandPostings(a.Get("a", "3"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{102}, got)
+
+	got, err = ExpandPostings(a.All())
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{1, 2, 101, 102}, got)
+
+	keys := a.SortedKeys()
+	testutil.Equals(t, []labels.Label{
+		{Name: "", Value: ""},
+		{Name: "a", Value: "1"},
+		{Name: "a", Value: "2"},
+		{Name: "a", Value: "3"},
+	}, keys)
+}
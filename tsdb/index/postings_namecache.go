@@ -0,0 +1,50 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+// LabelValuesUnion returns the postings list of every ID that has any value
+// for the given label name, i.e. the union of Get(name, v) over all v. The
+// result is cached per name and only rebuilt after an Add or Delete that
+// touches that name, which makes repeated `name=~".*"`-style queries cheap.
+func (p *MemPostings) LabelValuesUnion(name string) Postings {
+	p.mtx.RLock()
+	version := p.nameVersion[name]
+	if cached, ok := p.nameUnionCache[name]; ok && cached.version == version {
+		ids := cached.ids
+		p.mtx.RUnlock()
+		return newListPostings(ids...)
+	}
+	e := p.m[name]
+	p.mtx.RUnlock()
+
+	if len(e) == 0 {
+		return EmptyPostings()
+	}
+
+	its := make([]Postings, 0, len(e))
+	for _, list := range e {
+		its = append(its, newListPostings(list...))
+	}
+	ids, err := ExpandPostings(Merge(its...))
+	if err != nil {
+		// ListPostings never errors, so Merge of them can't either.
+		return EmptyPostings()
+	}
+
+	p.mtx.Lock()
+	p.nameUnionCache[name] = nameUnion{version: version, ids: ids}
+	p.mtx.Unlock()
+
+	return newListPostings(ids...)
+}
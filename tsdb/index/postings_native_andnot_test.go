@@ -0,0 +1,62 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/tsdb/index/roaring"
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestNativeAndNot_MatchesIteratorPath(t *testing.T) {
+	full := bitmapOf(1, 2, 3, 70000, 70001)
+	drop := bitmapOf(2, 70000)
+
+	got, err := ExpandPostings(Without(
+		roaring.NewRoaringBitmapIterator(full),
+		roaring.NewRoaringBitmapIterator(drop),
+	))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{1, 3, 70001}, got)
+}
+
+func TestNativeAndNot_FallsBackForMixedInputs(t *testing.T) {
+	full := bitmapOf(1, 2, 3)
+	got, err := ExpandPostings(Without(roaring.NewRoaringBitmapIterator(full), newListPostings(2)))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{1, 3}, got)
+}
+
+func BenchmarkWithout_NativeAndNot_10MBaseMinus2MDrop(b *testing.B) {
+	base := roaring.NewRoaringBitmapPosting()
+	for i := uint64(0); i < 10000000; i++ {
+		base.Add(i)
+	}
+	drop := roaring.NewRoaringBitmapPosting()
+	for i := uint64(0); i < 10000000; i += 5 {
+		drop.Add(i)
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		p := Without(roaring.NewRoaringBitmapIterator(base), roaring.NewRoaringBitmapIterator(drop))
+		for p.Next() {
+		}
+		if err := p.Err(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
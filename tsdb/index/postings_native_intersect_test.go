@@ -0,0 +1,125 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/tsdb/index/roaring"
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestNativeIntersect_MatchesIteratorPath(t *testing.T) {
+	a := bitmapOf(1, 2, 3, 70000)
+	b := bitmapOf(2, 70000, 70001)
+
+	got, err := ExpandPostings(Intersect(roaring.NewRoaringBitmapIterator(a), roaring.NewRoaringBitmapIterator(b)))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{2, 70000}, got)
+}
+
+func TestNativeIntersect_FallsBackForNonRoaring(t *testing.T) {
+	a := bitmapOf(1, 2, 3)
+	got, err := ExpandPostings(Intersect(roaring.NewRoaringBitmapIterator(a), newListPostings(2, 3)))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{2, 3}, got)
+}
+
+func bigBitmap(n int) *roaring.RoaringBitmapPosting {
+	bm := roaring.NewRoaringBitmapPosting()
+	for i := 0; i < n; i++ {
+		bm.Add(uint64(i))
+	}
+	return bm
+}
+
+func BenchmarkIntersect_NativeVsIterator(b *testing.B) {
+	huge1 := bigBitmap(1000000)
+	huge2 := roaring.NewRoaringBitmapPosting()
+	for i := 500000; i < 1500000; i++ {
+		huge2.Add(uint64(i))
+	}
+	tiny := bigBitmap(10)
+
+	b.Run("TinyIntersectHuge", func(bench *testing.B) {
+		bench.ReportAllocs()
+		for i := 0; i < bench.N; i++ {
+			p := Intersect(roaring.NewRoaringBitmapIterator(tiny), roaring.NewRoaringBitmapIterator(huge1))
+			for p.Next() {
+			}
+		}
+	})
+
+	b.Run("HugeIntersectHuge", func(bench *testing.B) {
+		bench.ReportAllocs()
+		for i := 0; i < bench.N; i++ {
+			p := Intersect(roaring.NewRoaringBitmapIterator(huge1), roaring.NewRoaringBitmapIterator(huge2))
+			for p.Next() {
+			}
+		}
+	})
+}
+
+// BenchmarkIntersect_RoaringVsLongPostings1 mirrors BenchmarkIntersect's
+// LongPostings1 case (the same a/b series), but with both operands backed
+// by a RoaringBitmapPosting instead of a plain list.
+//
+// LongPostings1 is deliberately skewed (b is tiny next to a), which is
+// exactly the shape NativeIntersectCardinalityRatio is meant to route away
+// from the native container-AND path and into the iterator fallback, so
+// seeking can skip most of a's containers outright; see
+// BenchmarkIntersect_NativeVsIterator/HugeIntersectHuge for the case where
+// cardinalities are close and the native path wins instead.
+func BenchmarkIntersect_RoaringVsLongPostings1(b *testing.B) {
+	aBM := roaring.NewRoaringBitmapPosting()
+	for i := 0; i < 10000000; i += 2 {
+		aBM.Add(uint64(i))
+	}
+	bBM := roaring.NewRoaringBitmapPosting()
+	for i := 5000000; i < 5000100; i += 4 {
+		bBM.Add(uint64(i))
+	}
+	for i := 5090000; i < 5090600; i += 4 {
+		bBM.Add(uint64(i))
+	}
+
+	var aList, bList []uint64
+	for i := 0; i < 10000000; i += 2 {
+		aList = append(aList, uint64(i))
+	}
+	for i := 5000000; i < 5000100; i += 4 {
+		bList = append(bList, uint64(i))
+	}
+	for i := 5090000; i < 5090600; i += 4 {
+		bList = append(bList, uint64(i))
+	}
+
+	b.Run("List", func(bench *testing.B) {
+		bench.ReportAllocs()
+		for i := 0; i < bench.N; i++ {
+			if _, err := ExpandPostings(Intersect(newListPostings(aList...), newListPostings(bList...))); err != nil {
+				bench.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Roaring", func(bench *testing.B) {
+		bench.ReportAllocs()
+		for i := 0; i < bench.N; i++ {
+			if _, err := ExpandPostings(Intersect(roaring.NewRoaringBitmapIterator(aBM), roaring.NewRoaringBitmapIterator(bBM))); err != nil {
+				bench.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,63 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/tsdb/index/roaring"
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestNativeMerge_MatchesHeapMergePath(t *testing.T) {
+	a := bitmapOf(1, 2, 3, 70000)
+	b := bitmapOf(2, 70000, 70001)
+	c := bitmapOf(5)
+
+	got, err := ExpandPostings(Merge(
+		roaring.NewRoaringBitmapIterator(a),
+		roaring.NewRoaringBitmapIterator(b),
+		roaring.NewRoaringBitmapIterator(c),
+	))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{1, 2, 3, 5, 70000, 70001}, got)
+}
+
+func TestNativeMerge_FallsBackForMixedInputs(t *testing.T) {
+	a := bitmapOf(1, 2, 3)
+	got, err := ExpandPostings(Merge(roaring.NewRoaringBitmapIterator(a), newListPostings(2, 3, 4)))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{1, 2, 3, 4}, got)
+}
+
+func BenchmarkMerge_100000SmallRoaringBitmaps(b *testing.B) {
+	bitmaps := make([]*roaring.RoaringBitmapPosting, 100000)
+	for i := range bitmaps {
+		bm := roaring.NewRoaringBitmapPosting()
+		bm.Add(uint64(i))
+		bitmaps[i] = bm
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		ps := make([]Postings, len(bitmaps))
+		for i, bm := range bitmaps {
+			ps[i] = roaring.NewRoaringBitmapIterator(bm)
+		}
+		if _, err := ExpandPostings(Merge(ps...)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+// selector is implemented by Postings backed by a random-access structure,
+// such as a roaring bitmap, that can answer a rank query directly instead
+// of iterating.
+type selector interface {
+	Select(n int) (uint64, bool)
+}
+
+// NthPostings returns the n-th (0-based) ID that p would emit, without
+// requiring the caller to drive the full iteration. If p is backed by a
+// structure that supports direct rank selection it is used; otherwise
+// NthPostings falls back to calling Next n+1 times. It returns false if p
+// has fewer than n+1 members.
+func NthPostings(p Postings, n int) (uint64, bool) {
+	if n < 0 {
+		return 0, false
+	}
+	if s, ok := p.(selector); ok {
+		return s.Select(n)
+	}
+	for i := 0; ; i++ {
+		if !p.Next() {
+			return 0, false
+		}
+		if i == n {
+			return p.At(), true
+		}
+	}
+}
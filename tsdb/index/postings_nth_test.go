@@ -0,0 +1,47 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestNthPostings(t *testing.T) {
+	var want []uint64
+	for i := uint64(1); i <= 100; i++ {
+		want = append(want, i)
+	}
+
+	cases := []struct {
+		n     int
+		id    uint64
+		found bool
+	}{
+		{0, 1, true},
+		{49, 50, true},
+		{99, 100, true},
+		{100, 0, false},
+		{-1, 0, false},
+	}
+
+	for _, c := range cases {
+		got, ok := NthPostings(newListPostings(want...), c.n)
+		testutil.Equals(t, c.found, ok)
+		if c.found {
+			testutil.Equals(t, c.id, got)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+// PostingsPlan is a small ergonomics layer over Intersect/Merge/Without for
+// building up a postings query piece by piece instead of nesting those
+// calls by hand. It does not introduce any new combinator logic; Build just
+// composes the existing ones.
+type PostingsPlan struct {
+	and []Postings
+	or  []Postings
+	not []Postings
+}
+
+// NewPostingsPlan returns an empty plan. A plan with no And or Or
+// constraint builds to EmptyPostings, whatever Not constraints it holds.
+func NewPostingsPlan() *PostingsPlan {
+	return &PostingsPlan{}
+}
+
+// And requires every returned ID to also be present in p.
+func (pl *PostingsPlan) And(p Postings) *PostingsPlan {
+	pl.and = append(pl.and, p)
+	return pl
+}
+
+// Or adds an alternative set of postings: an ID matches if it is present in
+// any of the Or'd postings, in addition to satisfying every And.
+func (pl *PostingsPlan) Or(ps ...Postings) *PostingsPlan {
+	pl.or = append(pl.or, ps...)
+	return pl
+}
+
+// Not excludes every ID present in p from the result.
+func (pl *PostingsPlan) Not(p Postings) *PostingsPlan {
+	pl.not = append(pl.not, p)
+	return pl
+}
+
+// Build composes the accumulated And/Or/Not constraints into a single
+// Postings using Intersect, Merge, and Without.
+func (pl *PostingsPlan) Build() Postings {
+	var result Postings
+
+	switch len(pl.or) {
+	case 0:
+		result = nil
+	case 1:
+		result = pl.or[0]
+	default:
+		result = Merge(pl.or...)
+	}
+
+	ands := pl.and
+	if result != nil {
+		ands = append([]Postings{result}, ands...)
+	}
+	switch len(ands) {
+	case 0:
+		result = EmptyPostings()
+	case 1:
+		result = ands[0]
+	default:
+		result = Intersect(ands...)
+	}
+
+	for _, p := range pl.not {
+		result = Without(result, p)
+	}
+	return result
+}
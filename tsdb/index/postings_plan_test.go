@@ -0,0 +1,42 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestPostingsPlan_EquivalentToIntersectWithMerge(t *testing.T) {
+	a := newListPostings(21, 22, 23, 24, 25, 30)
+
+	plan := NewPostingsPlan().
+		And(a).
+		Or(newListPostings(10, 20, 30), newListPostings(15, 26, 30))
+
+	res, err := ExpandPostings(plan.Build())
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{30}, res)
+}
+
+func TestPostingsPlan_Not(t *testing.T) {
+	plan := NewPostingsPlan().
+		And(newListPostings(1, 2, 3, 4)).
+		Not(newListPostings(2, 4))
+
+	res, err := ExpandPostings(plan.Build())
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{1, 3}, res)
+}
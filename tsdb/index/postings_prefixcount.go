@@ -0,0 +1,29 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+// PrefixCount returns a closure that yields each ID of p alongside how many
+// IDs (including this one) have been seen so far. It works for any
+// Postings and is meant for plotting a cumulative distribution of series
+// density.
+func PrefixCount(p Postings) func() (id uint64, runningCount uint64, ok bool) {
+	var count uint64
+	return func() (uint64, uint64, bool) {
+		if !p.Next() {
+			return 0, count, false
+		}
+		count++
+		return p.At(), count, true
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestPrefixCount(t *testing.T) {
+	next := PrefixCount(newListPostings(10, 20, 30))
+
+	var ids, counts []uint64
+	for {
+		id, count, ok := next()
+		if !ok {
+			break
+		}
+		ids = append(ids, id)
+		counts = append(counts, count)
+	}
+
+	testutil.Equals(t, []uint64{10, 20, 30}, ids)
+	testutil.Equals(t, []uint64{1, 2, 3}, counts)
+}
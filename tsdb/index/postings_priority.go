@@ -0,0 +1,131 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import "container/heap"
+
+// PriorityPostings is a Postings iterator produced by MergePriority. In
+// addition to the usual iteration it exposes which source won the current
+// ID when more than one source carried it.
+type PriorityPostings interface {
+	Postings
+	// Winner returns the index into the sources slice passed to
+	// MergePriority that supplied the value returned by the most recent
+	// At(). It is only meaningful after a successful Next or Seek.
+	Winner() int
+}
+
+// MergePriority returns a new iterator over the union of sources. Sources
+// earlier in the slice take priority: when two or more sources carry the
+// same ID, the lowest-index source is reported as the Winner for that ID.
+// This supports overwrite semantics across overlapping, prioritized blocks.
+func MergePriority(sources []Postings) PriorityPostings {
+	h := make(prioritySourceHeap, 0, len(sources))
+	for i, p := range sources {
+		if p.Next() {
+			h = append(h, prioritySource{Postings: p, idx: i})
+		} else if p.Err() != nil {
+			return &mergePriorityPostings{err: p.Err()}
+		}
+	}
+	if len(h) == 0 {
+		return &mergePriorityPostings{}
+	}
+	heap.Init(&h)
+	return &mergePriorityPostings{h: h}
+}
+
+type prioritySource struct {
+	Postings
+	idx int
+}
+
+type prioritySourceHeap []prioritySource
+
+func (h prioritySourceHeap) Len() int            { return len(h) }
+func (h prioritySourceHeap) Less(i, j int) bool  { return h[i].At() < h[j].At() }
+func (h prioritySourceHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *prioritySourceHeap) Push(x interface{}) { *h = append(*h, x.(prioritySource)) }
+func (h *prioritySourceHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+type mergePriorityPostings struct {
+	h           prioritySourceHeap
+	initialized bool
+	cur         uint64
+	winner      int
+	err         error
+}
+
+func (it *mergePriorityPostings) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.initialized {
+		// Advance every source tied at the current value before looking for
+		// the next one.
+		for it.h.Len() > 0 && it.h[0].At() == it.cur {
+			top := heap.Pop(&it.h).(prioritySource)
+			if top.Next() {
+				heap.Push(&it.h, top)
+			} else if top.Err() != nil {
+				it.err = top.Err()
+				return false
+			}
+		}
+	}
+	it.initialized = true
+	if it.h.Len() == 0 {
+		return false
+	}
+
+	min := it.h[0].At()
+	winner := it.h[0].idx
+	var tied []prioritySource
+	for it.h.Len() > 0 && it.h[0].At() == min {
+		top := heap.Pop(&it.h).(prioritySource)
+		if top.idx < winner {
+			winner = top.idx
+		}
+		tied = append(tied, top)
+	}
+	for _, p := range tied {
+		heap.Push(&it.h, p)
+	}
+
+	it.cur = min
+	it.winner = winner
+	return true
+}
+
+func (it *mergePriorityPostings) Seek(id uint64) bool {
+	if it.err != nil {
+		return false
+	}
+	for !it.initialized || it.cur < id {
+		if !it.Next() {
+			return false
+		}
+	}
+	return true
+}
+
+func (it *mergePriorityPostings) At() uint64  { return it.cur }
+func (it *mergePriorityPostings) Err() error  { return it.err }
+func (it *mergePriorityPostings) Winner() int { return it.winner }
@@ -0,0 +1,40 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestMergePriority(t *testing.T) {
+	// Source 0 is higher priority (e.g. the fresher block) and shares IDs 2
+	// and 4 with source 1.
+	a := newListPostings(1, 2, 4)
+	b := newListPostings(2, 3, 4, 5)
+
+	it := MergePriority([]Postings{a, b})
+
+	var ids []uint64
+	var winners []int
+	for it.Next() {
+		ids = append(ids, it.At())
+		winners = append(winners, it.Winner())
+	}
+	testutil.Ok(t, it.Err())
+
+	testutil.Equals(t, []uint64{1, 2, 3, 4, 5}, ids)
+	testutil.Equals(t, []int{0, 0, 1, 0, 1}, winners)
+}
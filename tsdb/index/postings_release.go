@@ -0,0 +1,69 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+// postingsCloser is implemented by Postings that hold a resource, such as a
+// pooled buffer or an mmap-backed bitmap, that must be released after use.
+// Most Postings implementations do not need it; it is optional by design so
+// plain in-memory iterators stay unchanged.
+type postingsCloser interface {
+	Close() error
+}
+
+// ReleasePostings closes p if it (or, for the combinators below, any of its
+// children) holds a releasable resource. It is safe to call on any Postings,
+// including ones that never need releasing.
+func ReleasePostings(p Postings) error {
+	if c, ok := p.(postingsCloser); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Close releases every leaf iterator that supports it. It allows
+// Intersect's result to be passed straight to ReleasePostings.
+func (it *intersectPostings) Close() error {
+	var err error
+	for _, p := range it.arr {
+		if cerr := ReleasePostings(p); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Close releases the source iterators of a Merge result that are still in
+// its heap. A source that was empty when the merge was built, or that has
+// since been exhausted, has left the heap and is not closed.
+func (it *mergedPostings) Close() error {
+	var err error
+	for _, p := range it.h {
+		if cerr := ReleasePostings(p); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Close releases both inputs of a Without result.
+func (rp *removedPostings) Close() error {
+	var err error
+	if cerr := ReleasePostings(rp.full); cerr != nil {
+		err = cerr
+	}
+	if cerr := ReleasePostings(rp.remove); cerr != nil && err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,50 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+// closeCountingPostings wraps a Postings and records whether Close was
+// called, to stand in for a pooled or mmap-backed iterator in tests.
+type closeCountingPostings struct {
+	Postings
+	closed bool
+}
+
+func (p *closeCountingPostings) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestReleasePostings_PropagatesThroughIntersect(t *testing.T) {
+	leaves := []*closeCountingPostings{
+		{Postings: newListPostings(1, 2, 3)},
+		{Postings: newListPostings(2, 3, 4)},
+	}
+	its := make([]Postings, len(leaves))
+	for i, l := range leaves {
+		its[i] = l
+	}
+
+	merged := Intersect(its...)
+	testutil.Ok(t, ReleasePostings(merged))
+
+	for _, l := range leaves {
+		testutil.Assert(t, l.closed, "expected leaf iterator to be closed")
+	}
+}
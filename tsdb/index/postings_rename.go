@@ -0,0 +1,70 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import "github.com/prometheus/prometheus/pkg/labels"
+
+// Rename moves the postings list stored under (oldName, oldValue) to
+// (newName, newValue), merging it into any postings already present at the
+// destination. The source key is left empty. It is meant for label
+// rewriting during compaction, where the underlying series IDs do not
+// change, so allPostingsKey is untouched.
+func (p *MemPostings) Rename(oldName, oldValue, newName, newValue string) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	srcMap := p.m[oldName]
+	if srcMap == nil {
+		return
+	}
+	src := srcMap[oldValue]
+	if src == nil {
+		return
+	}
+	delete(srcMap, oldValue)
+	if len(srcMap) == 0 {
+		delete(p.m, oldName)
+	}
+	p.nameVersion[oldName]++
+	p.nameVersion[newName]++
+
+	// Keep the recorded label sets in step with m for Delete.
+	for _, id := range src {
+		if lset, ok := p.series[id]; ok {
+			repl := make([]labels.Label, len(lset))
+			copy(repl, lset)
+			for i, l := range repl {
+				if l.Name == oldName && l.Value == oldValue {
+					repl[i] = labels.Label{Name: newName, Value: newValue}
+				}
+			}
+			p.series[id] = labels.New(repl...)
+		}
+	}
+
+	dstMap, ok := p.m[newName]
+	if !ok {
+		dstMap = map[string][]uint64{}
+		p.m[newName] = dstMap
+	}
+	dst := dstMap[newValue]
+	if dst == nil {
+		dstMap[newValue] = src
+		return
+	}
+
+	// ListPostings never errors, so Merge of two of them can't either.
+	merged, _ := ExpandPostings(Merge(newListPostings(dst...), newListPostings(src...)))
+	dstMap[newValue] = merged
+}
@@ -0,0 +1,52 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+// seekCachePostings wraps a Postings and memoizes the last Seek target and
+// result, so that a repeated Seek to the same or a smaller value is
+// answered without touching the wrapped iterator. This is useful in k-way
+// intersections, where the same iterator is often re-Seek'd to nearby
+// targets because many lists share a head value.
+type seekCachePostings struct {
+	Postings
+
+	hasLast  bool
+	lastSeek uint64
+	lastOK   bool
+}
+
+// CacheSeeks wraps p so that repeated Seeks to the same or a smaller value
+// are served from a one-entry cache instead of re-invoking the wrapped
+// Postings. It does not change the sequence of values produced by Next/At.
+func CacheSeeks(p Postings) Postings {
+	return &seekCachePostings{Postings: p}
+}
+
+func (p *seekCachePostings) Seek(v uint64) bool {
+	if p.hasLast && v <= p.lastSeek {
+		return p.lastOK
+	}
+	ok := p.Postings.Seek(v)
+	p.hasLast = true
+	p.lastSeek = v
+	p.lastOK = ok
+	return ok
+}
+
+func (p *seekCachePostings) Next() bool {
+	// The cached Seek result is only valid relative to the iterator position
+	// it was taken at; advancing via Next invalidates it.
+	p.hasLast = false
+	return p.Postings.Next()
+}
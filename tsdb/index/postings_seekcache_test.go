@@ -0,0 +1,51 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestCacheSeeks(t *testing.T) {
+	p := CacheSeeks(newListPostings(10, 20, 30, 40))
+
+	testutil.Assert(t, p.Seek(15), "expected Seek(15) to find 20")
+	testutil.Equals(t, uint64(20), p.At())
+
+	// Re-Seek to the same or a smaller target should be served from cache
+	// and leave the position untouched.
+	testutil.Assert(t, p.Seek(15), "expected cached Seek(15) to hit")
+	testutil.Assert(t, p.Seek(5), "expected cached Seek(5) to hit")
+	testutil.Equals(t, uint64(20), p.At())
+
+	testutil.Assert(t, p.Seek(35), "expected Seek(35) to find 40")
+	testutil.Equals(t, uint64(40), p.At())
+
+	testutil.Assert(t, !p.Seek(100), "expected Seek past the end to fail")
+}
+
+func BenchmarkManyPostingsIntersectCached(b *testing.B) {
+	const n = 64
+	for i := 0; i < b.N; i++ {
+		its := make([]Postings, n)
+		for j := range its {
+			its[j] = CacheSeeks(newListPostings(1, 2, 3, 1000, 1001, 1002))
+		}
+		if _, err := ExpandPostings(Intersect(its...)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+// Shard returns a new MemPostings containing only the series whose ID
+// satisfies id%total == index, with every postings list filtered down to
+// that subset. The source index must already be ordered; the result is
+// too, since filtering a sorted list by a predicate preserves order.
+func (p *MemPostings) Shard(total, index uint64) *MemPostings {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	out := NewMemPostings()
+	for name, values := range p.m {
+		for value, list := range values {
+			var shard []uint64
+			for _, id := range list {
+				if id%total == index {
+					shard = append(shard, id)
+				}
+			}
+			if len(shard) == 0 {
+				continue
+			}
+			nm, ok := out.m[name]
+			if !ok {
+				nm = map[string][]uint64{}
+				out.m[name] = nm
+			}
+			nm[value] = shard
+		}
+	}
+	return out
+}
@@ -0,0 +1,48 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestMemPostings_Shard(t *testing.T) {
+	p := NewMemPostings()
+	for id := uint64(1); id <= 20; id++ {
+		p.Add(id, labels.FromStrings("a", "1", "odd", map[bool]string{true: "yes", false: "no"}[id%2 == 1]))
+	}
+
+	const total = uint64(4)
+	var shards []*MemPostings
+	for i := uint64(0); i < total; i++ {
+		shards = append(shards, p.Shard(total, i))
+	}
+
+	for _, key := range p.SortedKeys() {
+		want, err := ExpandPostings(p.Get(key.Name, key.Value))
+		testutil.Ok(t, err)
+
+		var ps []Postings
+		for _, s := range shards {
+			ps = append(ps, s.Get(key.Name, key.Value))
+		}
+		got, err := ExpandPostings(Merge(ps...))
+		testutil.Ok(t, err)
+
+		testutil.Equals(t, want, got)
+	}
+}
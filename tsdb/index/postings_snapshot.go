@@ -0,0 +1,164 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/tsdb/index/roaring"
+)
+
+// postingsSnapshotVersion identifies the on-disk layout written by
+// WriteTo/ReadMemPostings. It versions the outer name/value framing only;
+// each postings list nested inside carries its own wire version courtesy of
+// RoaringBitmapPosting.WriteTo, so the two can evolve independently.
+const postingsSnapshotVersion = 1
+
+// WriteTo streams a checkpoint of every postings list in p to w: a version
+// header, a count of label/value pairs, and then, for each pair, the name,
+// the value, and the postings list itself encoded via
+// RoaringBitmapPosting.WriteTo. The per-list encoding is self-delimiting, so
+// no outer length prefix is needed around it.
+func (p *MemPostings) WriteTo(w io.Writer) (int64, error) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	cw := &countingWriter{w: w}
+
+	if err := binary.Write(cw, binary.BigEndian, uint8(postingsSnapshotVersion)); err != nil {
+		return cw.n, err
+	}
+
+	var count uint32
+	for _, e := range p.m {
+		count += uint32(len(e))
+	}
+	if err := binary.Write(cw, binary.BigEndian, count); err != nil {
+		return cw.n, err
+	}
+
+	for name, e := range p.m {
+		for value, list := range e {
+			if err := writeLengthPrefixed(cw, name); err != nil {
+				return cw.n, err
+			}
+			if err := writeLengthPrefixed(cw, value); err != nil {
+				return cw.n, err
+			}
+
+			bm := roaring.NewRoaringBitmapPosting()
+			for _, id := range list {
+				bm.Add(id)
+			}
+			if _, err := bm.WriteTo(cw); err != nil {
+				return cw.n, err
+			}
+		}
+	}
+	return cw.n, nil
+}
+
+// ReadMemPostings reconstructs a MemPostings from a checkpoint written by
+// WriteTo. The returned index is ready for reads and writes; series label
+// sets, used internally by Delete, are rebuilt from the restored postings
+// lists.
+func ReadMemPostings(r io.Reader) (*MemPostings, error) {
+	var version uint8
+	if err := binary.Read(r, binary.BigEndian, &version); err != nil {
+		return nil, err
+	}
+	if version != postingsSnapshotVersion {
+		return nil, fmt.Errorf("index: unsupported MemPostings snapshot version %d", version)
+	}
+	var count uint32
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return nil, err
+	}
+
+	p := NewMemPostings()
+	seriesLabels := map[uint64][]labels.Label{}
+
+	for i := uint32(0); i < count; i++ {
+		name, err := readLengthPrefixed(r)
+		if err != nil {
+			return nil, err
+		}
+		value, err := readLengthPrefixed(r)
+		if err != nil {
+			return nil, err
+		}
+
+		bm := roaring.NewRoaringBitmapPosting()
+		if _, err := bm.ReadFrom(r); err != nil {
+			return nil, err
+		}
+
+		list := make([]uint64, 0, bm.Cardinality())
+		for it := roaring.NewRoaringBitmapIterator(bm); it.Next(); {
+			list = append(list, it.At())
+		}
+
+		nm, ok := p.m[name]
+		if !ok {
+			nm = map[string][]uint64{}
+			p.m[name] = nm
+		}
+		nm[value] = list
+
+		if name != allPostingsKey.Name {
+			for _, id := range list {
+				seriesLabels[id] = append(seriesLabels[id], labels.Label{Name: name, Value: value})
+			}
+		}
+	}
+
+	for id, lset := range seriesLabels {
+		p.series[id] = labels.New(lset...)
+	}
+	return p, nil
+}
+
+func writeLengthPrefixed(w io.Writer, s string) error {
+	if err := binary.Write(w, binary.BigEndian, uint16(len(s))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+func readLengthPrefixed(r io.Reader) (string, error) {
+	var n uint16
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return "", err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestMemPostings_WriteTo_ReadMemPostings_RoundTrip(t *testing.T) {
+	p := NewMemPostings()
+	p.Add(1, labels.FromStrings("__name__", "up", "job", "a"))
+	p.Add(2, labels.FromStrings("__name__", "up", "job", "b"))
+	p.Add(3, labels.FromStrings("__name__", "down", "job", "a"))
+	p.Add(4, labels.FromStrings("__name__", "down", "job", "b", "extra", "x"))
+
+	var buf bytes.Buffer
+	n, err := p.WriteTo(&buf)
+	testutil.Ok(t, err)
+	testutil.Equals(t, int64(buf.Len()), n)
+
+	restored, err := ReadMemPostings(&buf)
+	testutil.Ok(t, err)
+
+	for _, pair := range []struct{ name, value string }{
+		{"__name__", "up"},
+		{"__name__", "down"},
+		{"job", "a"},
+		{"job", "b"},
+		{"extra", "x"},
+		{"missing", "missing"},
+	} {
+		want, err := ExpandPostings(p.Get(pair.name, pair.value))
+		testutil.Ok(t, err)
+		got, err := ExpandPostings(restored.Get(pair.name, pair.value))
+		testutil.Ok(t, err)
+		testutil.Equals(t, want, got)
+	}
+
+	wantAll, err := ExpandPostings(p.All())
+	testutil.Ok(t, err)
+	gotAll, err := ExpandPostings(restored.All())
+	testutil.Ok(t, err)
+	testutil.Equals(t, wantAll, gotAll)
+
+	testutil.Equals(t, p.LabelNames(), restored.LabelNames())
+
+	// Delete must still work against a restored index, which means its
+	// series inverted index was rebuilt correctly.
+	restored.Delete(map[uint64]struct{}{4: {}})
+	expanded, err := ExpandPostings(restored.Get("extra", "x"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, 0 == len(expanded), "expected empty postings, got %v", expanded)
+}
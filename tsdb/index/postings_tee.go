@@ -0,0 +1,110 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import "sync"
+
+// teeBuffer is the state shared by the two iterators returned from Tee. It
+// buffers every value read from the source so that whichever tee is behind
+// can catch up, and drops values from the front of the buffer once both
+// tees have moved past them.
+type teeBuffer struct {
+	mtx sync.Mutex
+	p   Postings
+	buf []uint64
+	// base is the source index of buf[0]; buf[i] holds the value at
+	// source index base+i.
+	base int
+	read [2]int
+}
+
+// at returns the value at source index idx, pulling from the underlying
+// iterator if it hasn't been read yet. reader identifies which tee is
+// asking, so the buffer can drop values both tees have already passed.
+func (b *teeBuffer) at(reader, idx int) (uint64, bool) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	for b.base+len(b.buf) <= idx {
+		if !b.p.Next() {
+			return 0, false
+		}
+		b.buf = append(b.buf, b.p.At())
+	}
+	v := b.buf[idx-b.base]
+
+	b.read[reader] = idx + 1
+	minRead := b.read[0]
+	if b.read[1] < minRead {
+		minRead = b.read[1]
+	}
+	if drop := minRead - b.base; drop > 0 {
+		b.buf = b.buf[drop:]
+		b.base = minRead
+	}
+	return v, true
+}
+
+type teePostings struct {
+	shared  *teeBuffer
+	reader  int
+	idx     int
+	started bool
+	cur     uint64
+}
+
+func (it *teePostings) Next() bool {
+	if it.started {
+		it.idx++
+	}
+	it.started = true
+	v, ok := it.shared.at(it.reader, it.idx)
+	if !ok {
+		return false
+	}
+	it.cur = v
+	return true
+}
+
+func (it *teePostings) Seek(v uint64) bool {
+	if it.started && it.cur >= v {
+		return true
+	}
+	for it.Next() {
+		if it.cur >= v {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *teePostings) At() uint64 {
+	return it.cur
+}
+
+func (it *teePostings) Err() error {
+	return it.shared.p.Err()
+}
+
+// Tee returns two independent Postings iterators that both yield the same
+// sequence as p. p is read at most once per value, regardless of how the
+// two tees are interleaved; values are buffered only for the span between
+// whichever tee is ahead and whichever is behind, and dropped once both
+// have moved past them. A caller that only ever advances one tee and never
+// touches the other will buffer the entire remainder of p, so Tee is meant
+// for consumers that make roughly matched progress.
+func Tee(p Postings) (Postings, Postings) {
+	shared := &teeBuffer{p: p}
+	return &teePostings{shared: shared, reader: 0}, &teePostings{shared: shared, reader: 1}
+}
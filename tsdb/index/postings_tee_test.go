@@ -0,0 +1,77 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestTee_Interleaved(t *testing.T) {
+	a, b := Tee(newListPostings(1, 2, 3, 4, 5))
+
+	var gotA, gotB []uint64
+	for {
+		aOk := a.Next()
+		if aOk {
+			gotA = append(gotA, a.At())
+		}
+		bOk := b.Next()
+		if bOk {
+			gotB = append(gotB, b.At())
+		}
+		if !aOk && !bOk {
+			break
+		}
+	}
+
+	testutil.Equals(t, []uint64{1, 2, 3, 4, 5}, gotA)
+	testutil.Equals(t, []uint64{1, 2, 3, 4, 5}, gotB)
+}
+
+func TestTee_OneAheadOfOther(t *testing.T) {
+	a, b := Tee(newListPostings(1, 2, 3, 4, 5))
+
+	got, err := ExpandPostings(a)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{1, 2, 3, 4, 5}, got)
+
+	got, err = ExpandPostings(b)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{1, 2, 3, 4, 5}, got)
+}
+
+func TestTee_Seek(t *testing.T) {
+	a, b := Tee(newListPostings(1, 2, 3, 4, 5))
+
+	testutil.Assert(t, a.Seek(3), "expected a.Seek(3) to succeed")
+	testutil.Equals(t, uint64(3), a.At())
+
+	testutil.Assert(t, b.Seek(4), "expected b.Seek(4) to succeed")
+	testutil.Equals(t, uint64(4), b.At())
+
+	testutil.Assert(t, a.Next(), "expected a.Next() to succeed")
+	testutil.Equals(t, uint64(4), a.At())
+}
+
+func TestTee_Err(t *testing.T) {
+	want := errors.New("boom")
+	a, b := Tee(ErrPostings(want))
+
+	testutil.Assert(t, !a.Next(), "expected a.Next() to fail on an errored source")
+	testutil.Equals(t, want, a.Err())
+	testutil.Equals(t, want, b.Err())
+}
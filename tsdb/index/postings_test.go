@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/tsdb/index/roaring"
 	"github.com/prometheus/prometheus/util/testutil"
 )
 
@@ -34,6 +35,24 @@ func TestMemPostings_addFor(t *testing.T) {
 	testutil.Equals(t, []uint64{1, 2, 3, 4, 5, 6, 7, 8}, p.m[allPostingsKey.Name][allPostingsKey.Value])
 }
 
+func TestMemPostings_AllPostingsLabel(t *testing.T) {
+	p := NewMemPostings()
+	p.Add(1, labels.FromStrings("a", "1"))
+	p.Add(2, labels.FromStrings("a", "2"))
+
+	expanded, err := ExpandPostings(p.All())
+	testutil.Ok(t, err)
+
+	name, value := AllPostingsKey()
+	l := AllPostingsLabel()
+	testutil.Equals(t, name, l.Name)
+	testutil.Equals(t, value, l.Value)
+
+	got, err := ExpandPostings(p.Get(l.Name, l.Value))
+	testutil.Ok(t, err)
+	testutil.Equals(t, expanded, got)
+}
+
 func TestMemPostings_ensureOrder(t *testing.T) {
 	p := NewUnorderedMemPostings()
 	p.m["a"] = map[string][]uint64{}
@@ -62,6 +81,44 @@ func TestMemPostings_ensureOrder(t *testing.T) {
 	}
 }
 
+// TestMemPostings_ensureOrder_IdempotentAfterFurtherAdds checks that
+// EnsureOrder only ever needs to do its full sort once: after that first
+// call, Add's own insertion-sort repair keeps every list sorted on its own,
+// so later Adds followed by another EnsureOrder call leave every list
+// sorted without EnsureOrder re-touching them (it returns immediately once
+// p.ordered is true).
+func TestMemPostings_ensureOrder_IdempotentAfterFurtherAdds(t *testing.T) {
+	p := NewUnorderedMemPostings()
+	p.m["a"] = map[string][]uint64{}
+	for i := 0; i < 10; i++ {
+		l := make([]uint64, 10)
+		for j := range l {
+			l[j] = rand.Uint64()
+		}
+		p.m["a"][fmt.Sprintf("%d", i)] = l
+	}
+
+	p.EnsureOrder()
+	p.EnsureOrder() // Repeat call must be a cheap no-op, not a second sort.
+
+	p.Add(1, labels.FromStrings("a", "new"))
+	p.Add(2, labels.FromStrings("a", "new"))
+	p.Add(1, labels.FromStrings("a", "new")) // Out-of-order relative to the line above.
+
+	p.EnsureOrder()
+
+	for _, e := range p.m {
+		for _, l := range e {
+			ok := sort.SliceIsSorted(l, func(i, j int) bool {
+				return l[i] < l[j]
+			})
+			if !ok {
+				t.Fatalf("postings list %v is not sorted", l)
+			}
+		}
+	}
+}
+
 func TestIntersect(t *testing.T) {
 	a := newListPostings(1, 2, 3)
 	b := newListPostings(2, 3, 4)
@@ -719,6 +776,197 @@ func TestBigEndian(t *testing.T) {
 	})
 }
 
+func TestBigEndian64(t *testing.T) {
+	num := 1000
+	// mock a list as postings, including values above 2^32.
+	ls := make([]uint64, num)
+	ls[0] = 1 << 32
+	for i := 1; i < num; i++ {
+		ls[i] = ls[i-1] + uint64(rand.Int31n(25)) + 2
+	}
+
+	beLst := make([]byte, num*8)
+	for i := 0; i < num; i++ {
+		b := beLst[i*8 : i*8+8]
+		binary.BigEndian.PutUint64(b, ls[i])
+	}
+
+	t.Run("Iteration", func(t *testing.T) {
+		bep := newBigEndian64Postings(beLst)
+		for i := 0; i < num; i++ {
+			testutil.Assert(t, bep.Next() == true, "")
+			testutil.Equals(t, ls[i], bep.At())
+		}
+
+		testutil.Assert(t, bep.Next() == false, "")
+		testutil.Assert(t, bep.Err() == nil, "")
+	})
+
+	t.Run("Seek", func(t *testing.T) {
+		table := []struct {
+			seek  uint64
+			val   uint64
+			found bool
+		}{
+			{
+				ls[0] - 1, ls[0], true,
+			},
+			{
+				ls[4], ls[4], true,
+			},
+			{
+				ls[500] - 1, ls[500], true,
+			},
+			{
+				ls[600] + 1, ls[601], true,
+			},
+			{
+				ls[600] + 1, ls[601], true,
+			},
+			{
+				ls[600] + 1, ls[601], true,
+			},
+			{
+				ls[0], ls[601], true,
+			},
+			{
+				ls[600], ls[601], true,
+			},
+			{
+				ls[999], ls[999], true,
+			},
+			{
+				ls[999] + 10, ls[999], false,
+			},
+		}
+
+		bep := newBigEndian64Postings(beLst)
+
+		for _, v := range table {
+			testutil.Equals(t, v.found, bep.Seek(v.seek))
+			testutil.Equals(t, v.val, bep.At())
+			testutil.Assert(t, bep.Err() == nil, "")
+		}
+	})
+}
+
+// TestPostings_SeekPastEnd locks down the Postings contract documented on
+// the interface: Seek past the last value returns false, across every
+// concrete implementation. At's value in that state is explicitly
+// undefined, so it's not asserted on here.
+func TestPostings_SeekPastEnd(t *testing.T) {
+	cases := map[string]Postings{
+		"ListPostings":        newListPostings(1, 5, 10),
+		"bigEndianPostings":   newBigEndianPostings(encodeBigEndian32([]uint32{1, 5, 10})),
+		"bigEndian64Postings": newBigEndian64Postings(encodeBigEndian64([]uint64{1, 5, 10})),
+		"deltaVarintPostings": newDeltaVarintPostings(encodeDeltaVarint([]uint64{1, 5, 10})),
+		"removedPostings":     newRemovedPostings(newListPostings(1, 5, 10), newListPostings(5)),
+		"roaringIterator":     roaringIteratorOf(1, 5, 10),
+		"errPostings":         errPostings{},
+	}
+
+	for name, p := range cases {
+		t.Run(name, func(t *testing.T) {
+			testutil.Assert(t, !p.Seek(1000), "expected Seek past the end to return false for %s", name)
+		})
+	}
+}
+
+func encodeBigEndian32(vals []uint32) []byte {
+	b := make([]byte, len(vals)*4)
+	for i, v := range vals {
+		binary.BigEndian.PutUint32(b[i*4:], v)
+	}
+	return b
+}
+
+func encodeBigEndian64(vals []uint64) []byte {
+	b := make([]byte, len(vals)*8)
+	for i, v := range vals {
+		binary.BigEndian.PutUint64(b[i*8:], v)
+	}
+	return b
+}
+
+func roaringIteratorOf(vals ...uint64) Postings {
+	bm := roaring.NewRoaringBitmapPosting()
+	for _, v := range vals {
+		bm.Add(v)
+	}
+	return roaring.NewRoaringBitmapIterator(bm)
+}
+
+func TestExpandPostingsInto(t *testing.T) {
+	p := newListPostings(1, 2, 3)
+
+	buf := make([]uint64, 0, 3)
+	got, err := ExpandPostingsInto(p, buf)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{1, 2, 3}, got)
+
+	// Appending into a nonempty buffer keeps the existing elements.
+	buf = []uint64{0}
+	got, err = ExpandPostingsInto(newListPostings(1, 2), buf)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{0, 1, 2}, got)
+}
+
+// BenchmarkExpandPostings_BufferReuse compares ExpandPostings, which
+// allocates a fresh slice every call, against ExpandPostingsInto reusing one
+// buffer across calls.
+func BenchmarkExpandPostings_BufferReuse(b *testing.B) {
+	ids := make([]uint64, 10000)
+	for i := range ids {
+		ids[i] = uint64(i)
+	}
+
+	b.Run("ExpandPostings", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := ExpandPostings(newListPostings(ids...)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("ExpandPostingsInto", func(b *testing.B) {
+		b.ReportAllocs()
+		buf := make([]uint64, 0, len(ids))
+		for i := 0; i < b.N; i++ {
+			var err error
+			buf, err = ExpandPostingsInto(newListPostings(ids...), buf[:0])
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// TestCountPostings checks that CountPostings agrees with
+// len(ExpandPostings(...)) across several Postings implementations,
+// including the roaring-backed one that takes the Cardinality short-circuit.
+func TestCountPostings(t *testing.T) {
+	newCases := map[string]func() Postings{
+		"ListPostings":        func() Postings { return newListPostings(1, 2, 3, 4, 5) },
+		"bigEndianPostings":   func() Postings { return newBigEndianPostings(encodeBigEndian32([]uint32{1, 2, 3, 4, 5})) },
+		"bigEndian64Postings": func() Postings { return newBigEndian64Postings(encodeBigEndian64([]uint64{1, 2, 3, 4, 5})) },
+		"deltaVarintPostings": func() Postings { return newDeltaVarintPostings(encodeDeltaVarint([]uint64{1, 2, 3, 4, 5})) },
+		"roaringIterator":     func() Postings { return roaringIteratorOf(1, 2, 3, 4, 5) },
+		"empty":               func() Postings { return newListPostings() },
+	}
+
+	for name, newP := range newCases {
+		t.Run(name, func(t *testing.T) {
+			expanded, err := ExpandPostings(newP())
+			testutil.Ok(t, err)
+
+			count, err := CountPostings(newP())
+			testutil.Ok(t, err)
+			testutil.Equals(t, uint64(len(expanded)), count)
+		})
+	}
+}
+
 func TestIntersectWithMerge(t *testing.T) {
 	// One of the reproducible cases for:
 	// https://github.com/prometheus/prometheus/issues/2616
@@ -814,7 +1062,10 @@ func TestWithoutPostings(t *testing.T) {
 	}
 }
 
-func BenchmarkPostings_Stats(b *testing.B) {
+// buildStatsBenchmarkPostings returns a MemPostings with the same shape
+// BenchmarkPostings_Stats and BenchmarkPostings_LabelValues both exercise:
+// a realistic spread of label names, each with around 1000 values.
+func buildStatsBenchmarkPostings() *MemPostings {
 	p := NewMemPostings()
 
 	createPostingsLabelValues := func(name, valuePrefix string, count int) {
@@ -837,11 +1088,188 @@ func BenchmarkPostings_Stats(b *testing.B) {
 		createPostingsLabelValues(fmt.Sprintf("area-%d", i), "new_area_of_work-", 1e3)
 		createPostingsLabelValues(fmt.Sprintf("request_id-%d", i), "owner_name_work-", 1e3)
 	}
+	return p
+}
+
+func BenchmarkPostings_Stats(b *testing.B) {
+	p := buildStatsBenchmarkPostings()
+
 	b.ResetTimer()
 	for n := 0; n < b.N; n++ {
 		p.Stats("__name__")
 	}
+}
+
+func BenchmarkPostings_LabelValues(b *testing.B) {
+	p := buildStatsBenchmarkPostings()
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		p.LabelValues("__name__")
+	}
+}
+
+// TestMemPostings_Stats_Skewed builds a synthetic index where one metric
+// name and one series dominate everything else, and checks that Stats
+// surfaces them at the top of each top-10 list.
+func TestMemPostings_Stats_Skewed(t *testing.T) {
+	p := NewMemPostings()
+
+	// A long tail of ordinary series, each with a handful of labels.
+	for i := uint64(1); i <= 100; i++ {
+		p.Add(i, labels.FromStrings(
+			"__name__", fmt.Sprintf("metric_%d", i),
+			"job", "tail",
+		))
+	}
+
+	// One metric name shared by many series, so it dominates the
+	// cardinality-by-metric-name and label-value-pair stats. Each series
+	// gets its own job value so no other label/value pair ties it.
+	for i := uint64(101); i <= 300; i++ {
+		p.Add(i, labels.FromStrings("__name__", "hot_metric", "job", fmt.Sprintf("hot_%d", i)))
+	}
+
+	// One series with far more labels than any other, so it dominates the
+	// labels-per-series stat.
+	wideLabels := []string{"__name__", "wide_metric"}
+	for i := 0; i < 50; i++ {
+		wideLabels = append(wideLabels, fmt.Sprintf("lbl%d", i), "v")
+	}
+	p.Add(301, labels.FromStrings(wideLabels...))
+
+	stats := p.Stats("__name__")
+
+	testutil.Equals(t, "hot_metric", stats.CardinalityMetricsStats[0].Name)
+	testutil.Equals(t, uint64(200), stats.CardinalityMetricsStats[0].Count)
+
+	testutil.Equals(t, "__name__=hot_metric", stats.LabelValuePairsStats[0].Name)
+	testutil.Equals(t, uint64(200), stats.LabelValuePairsStats[0].Count)
+
+	testutil.Equals(t, "301", stats.LabelsPerSeriesStats[0].Name)
+	testutil.Equals(t, uint64(51), stats.LabelsPerSeriesStats[0].Count)
+}
+
+// TestMemPostings_AddBatch checks that a contiguous batch of ids ends up
+// indistinguishable from adding the same ids one at a time, including
+// staying sorted, and that the all-postings list and the series inverted
+// index used by Delete are kept up to date.
+func TestMemPostings_AddBatch(t *testing.T) {
+	p := NewMemPostings()
+	p.Add(0, labels.FromStrings("job", "other"))
+
+	ids := make([]uint64, 1000)
+	for i := range ids {
+		ids[i] = uint64(i + 1)
+	}
+	p.AddBatch(ids, labels.FromStrings("job", "batched"))
+
+	expanded, err := ExpandPostings(p.Get("job", "batched"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, ids, expanded)
 
+	expanded, err = ExpandPostings(p.All())
+	testutil.Ok(t, err)
+	testutil.Equals(t, append([]uint64{0}, ids...), expanded)
+
+	p.Delete(map[uint64]struct{}{500: {}})
+	expanded, err = ExpandPostings(p.Get("job", "batched"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, !contains(expanded, 500), "expected id 500 to be gone after Delete")
+}
+
+func contains(list []uint64, v uint64) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// TestMemPostings_Clone_StableAcrossDelete checks that a clone taken before
+// a Delete still returns the pre-delete postings, while the original index
+// reflects the delete as usual.
+func TestMemPostings_Clone_StableAcrossDelete(t *testing.T) {
+	p := NewMemPostings()
+	p.Add(1, labels.FromStrings("job", "a"))
+	p.Add(2, labels.FromStrings("job", "a"))
+	p.Add(3, labels.FromStrings("job", "b"))
+
+	clone := p.Clone()
+
+	p.Delete(map[uint64]struct{}{2: {}})
+
+	expanded, err := ExpandPostings(clone.Get("job", "a"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{1, 2}, expanded)
+
+	expanded, err = ExpandPostings(p.Get("job", "a"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{1}, expanded)
+
+	// Mutating the clone afterwards must not reach back into the original.
+	clone.Add(4, labels.FromStrings("job", "a"))
+	expanded, err = ExpandPostings(p.Get("job", "a"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{1}, expanded)
+}
+
+// BenchmarkMemPostings_ReplayAddLoop and BenchmarkMemPostings_ReplayAddBatch
+// replay 1M contiguous series IDs under one label set, the one-at-a-time
+// way and via AddBatch, to measure the lock-acquisition overhead AddBatch
+// is meant to avoid.
+func BenchmarkMemPostings_ReplayAddLoop(b *testing.B) {
+	const total = 1e6
+	lset := labels.FromStrings("job", "replay")
+
+	for i := 0; i < b.N; i++ {
+		p := NewMemPostings()
+		for id := uint64(1); id <= total; id++ {
+			p.Add(id, lset)
+		}
+	}
+}
+
+func BenchmarkMemPostings_ReplayAddBatch(b *testing.B) {
+	const total = 1e6
+	lset := labels.FromStrings("job", "replay")
+	ids := make([]uint64, total)
+	for i := range ids {
+		ids[i] = uint64(i + 1)
+	}
+
+	for i := 0; i < b.N; i++ {
+		p := NewMemPostings()
+		p.AddBatch(ids, lset)
+	}
+}
+
+// BenchmarkPostings_Delete deletes 1000 series out of a million-series
+// index, to measure the cost of Delete now that it goes straight to the
+// affected postings lists via the series inverted index instead of
+// scanning every list.
+func BenchmarkPostings_Delete(b *testing.B) {
+	const total = 1e6
+	const deleteCount = 1000
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		p := NewMemPostings()
+		for id := uint64(1); id <= total; id++ {
+			p.Add(id, labels.FromStrings(
+				"__name__", fmt.Sprintf("metric_%d", id%1000),
+				"instance", fmt.Sprintf("instance_%d", id%100),
+			))
+		}
+		deleted := make(map[uint64]struct{}, deleteCount)
+		for id := uint64(1); id <= deleteCount; id++ {
+			deleted[id] = struct{}{}
+		}
+		b.StartTimer()
+
+		p.Delete(deleted)
+	}
 }
 
 func TestMemPostings_Delete(t *testing.T) {
@@ -873,3 +1301,117 @@ func TestMemPostings_Delete(t *testing.T) {
 	testutil.Ok(t, err)
 	testutil.Assert(t, 0 == len(expanded), "expected empty postings, got %v", expanded)
 }
+
+// TestMemPostings_DeleteLabelValue checks that dropping a single
+// label/value pairing removes just that posting list and the matching ids
+// from the all-postings list, while leaving unrelated postings, including
+// other values under the same label name, untouched.
+func TestMemPostings_DeleteLabelValue(t *testing.T) {
+	p := NewMemPostings()
+	p.Add(1, labels.FromStrings("job", "decommissioned"))
+	p.Add(2, labels.FromStrings("job", "decommissioned"))
+	p.Add(3, labels.FromStrings("job", "keep", "lbl", "a"))
+
+	p.DeleteLabelValue("job", "decommissioned")
+
+	expanded, err := ExpandPostings(p.Get("job", "decommissioned"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, 0 == len(expanded), "expected empty postings, got %v", expanded)
+
+	expanded, err = ExpandPostings(p.Get("job", "keep"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{3}, expanded)
+
+	expanded, err = ExpandPostings(p.Get("lbl", "a"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{3}, expanded)
+
+	expanded, err = ExpandPostings(p.All())
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{3}, expanded)
+
+	testutil.Equals(t, []string{"keep"}, p.LabelValues("job"))
+}
+
+// TestMemPostings_Delete_AfterDeleteLabelValue checks that Delete still
+// cleans up a series' other label pairs even after DeleteLabelValue has
+// already dropped its p.series entry while decommissioning one of them.
+func TestMemPostings_Delete_AfterDeleteLabelValue(t *testing.T) {
+	p := NewMemPostings()
+	p.Add(1, labels.FromStrings("job", "decom", "env", "prod"))
+
+	p.DeleteLabelValue("job", "decom")
+	p.Delete(map[uint64]struct{}{1: {}})
+
+	expanded, err := ExpandPostings(p.Get("env", "prod"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, 0 == len(expanded), "expected empty postings, got %v", expanded)
+
+	expanded, err = ExpandPostings(p.All())
+	testutil.Ok(t, err)
+	testutil.Assert(t, 0 == len(expanded), "expected empty postings, got %v", expanded)
+}
+
+// TestMemPostings_DeleteLabelValue_Missing checks that deleting a name or
+// value that was never added is a harmless no-op.
+func TestMemPostings_DeleteLabelValue_Missing(t *testing.T) {
+	p := NewMemPostings()
+	p.Add(1, labels.FromStrings("job", "a"))
+
+	p.DeleteLabelValue("job", "missing")
+	p.DeleteLabelValue("missing", "a")
+
+	expanded, err := ExpandPostings(p.Get("job", "a"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{1}, expanded)
+}
+
+// TestMemPostings_All_ReflectsAddsBeforeCall interleaves Add calls with All,
+// checking that each call to All sees every id added before it, in
+// ascending order, and is unaffected by Adds that happen afterwards.
+func TestMemPostings_All_ReflectsAddsBeforeCall(t *testing.T) {
+	p := NewMemPostings()
+	p.Add(3, labels.FromStrings("lbl", "a"))
+	p.Add(1, labels.FromStrings("lbl", "b"))
+
+	all := p.All()
+
+	// Adding more series after taking the snapshot must not change it.
+	p.Add(2, labels.FromStrings("lbl", "c"))
+
+	expanded, err := ExpandPostings(all)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{1, 3}, expanded)
+
+	expanded, err = ExpandPostings(p.All())
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{1, 2, 3}, expanded)
+}
+
+// TestMemPostings_Get_StableDuringConcurrentDelete starts iterating a
+// postings list returned by Get, then deletes part of it out from under the
+// in-progress iteration, and confirms the iteration still yields the
+// pre-delete IDs. Get must hand back a snapshot rather than a view onto
+// p.m's live slice, since Delete replaces that slice with a new one built
+// from whatever the deletion map says should survive.
+func TestMemPostings_Get_StableDuringConcurrentDelete(t *testing.T) {
+	p := NewMemPostings()
+	p.Add(1, labels.FromStrings("lbl1", "a"))
+	p.Add(2, labels.FromStrings("lbl1", "a"))
+	p.Add(3, labels.FromStrings("lbl1", "a"))
+
+	it := p.Get("lbl1", "a")
+	testutil.Assert(t, it.Next(), "expected at least one posting")
+	testutil.Equals(t, uint64(1), it.At())
+
+	p.Delete(map[uint64]struct{}{
+		2: {},
+		3: {},
+	})
+
+	testutil.Assert(t, it.Next(), "expected the iterator to still see the deleted ID")
+	testutil.Equals(t, uint64(2), it.At())
+	testutil.Assert(t, it.Next(), "expected the iterator to still see the deleted ID")
+	testutil.Equals(t, uint64(3), it.At())
+	testutil.Assert(t, !it.Next(), "expected no more postings")
+}
@@ -0,0 +1,28 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import "github.com/prometheus/prometheus/tsdb/index/roaring"
+
+// GetWithTombstones returns the postings list for the given label pair with
+// every ID present in tombstones subtracted, so callers can skip
+// soft-deleted series without rewriting the index. A nil tombstones bitmap
+// behaves like Get.
+func (p *MemPostings) GetWithTombstones(name, value string, tombstones *roaring.RoaringBitmapPosting) Postings {
+	base := p.Get(name, value)
+	if tombstones == nil {
+		return base
+	}
+	return Without(base, roaring.NewRoaringBitmapIterator(tombstones))
+}
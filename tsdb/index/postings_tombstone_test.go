@@ -0,0 +1,36 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/tsdb/index/roaring"
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestMemPostings_GetWithTombstones(t *testing.T) {
+	p := NewMemPostings()
+	p.Add(1, labels.FromStrings("a", "1"))
+	p.Add(2, labels.FromStrings("a", "1"))
+	p.Add(3, labels.FromStrings("a", "1"))
+
+	tombstones := roaring.NewRoaringBitmapPosting()
+	tombstones.Add(2)
+
+	got, err := ExpandPostings(p.GetWithTombstones("a", "1", tombstones))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []uint64{1, 3}, got)
+}
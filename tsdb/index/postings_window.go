@@ -0,0 +1,55 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+// Windows buckets p's IDs into fixed-width, aligned windows of the given
+// width and returns a closure that yields one (windowStart, count) pair per
+// non-empty window in ascending order. It summarizes a postings list's ID
+// distribution without building an intermediate histogram.
+func Windows(p Postings, width uint64) func() (windowStart uint64, count uint64, ok bool) {
+	var (
+		pending  bool
+		curStart uint64
+		curCount uint64
+	)
+
+	return func() (uint64, uint64, bool) {
+		for {
+			if !p.Next() {
+				if pending {
+					pending = false
+					return curStart, curCount, true
+				}
+				return 0, 0, false
+			}
+			start := (p.At() / width) * width
+			if !pending {
+				pending = true
+				curStart = start
+				curCount = 1
+				continue
+			}
+			if start == curStart {
+				curCount++
+				continue
+			}
+			// Moved to a new window; emit the finished one and re-buffer.
+			result := curCount
+			resultStart := curStart
+			curStart = start
+			curCount = 1
+			return resultStart, result, true
+		}
+	}
+}
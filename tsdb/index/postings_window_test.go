@@ -0,0 +1,38 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestWindows(t *testing.T) {
+	p := newListPostings(1, 2, 100, 101, 102)
+	next := Windows(p, 100)
+
+	start, count, ok := next()
+	testutil.Assert(t, ok, "expected a window")
+	testutil.Equals(t, uint64(0), start)
+	testutil.Equals(t, uint64(2), count)
+
+	start, count, ok = next()
+	testutil.Assert(t, ok, "expected a window")
+	testutil.Equals(t, uint64(100), start)
+	testutil.Equals(t, uint64(3), count)
+
+	_, _, ok = next()
+	testutil.Assert(t, !ok, "expected no more windows")
+}
@@ -0,0 +1,53 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// AndCardinality returns the number of members r and o have in common,
+// without materializing the intersection. It walks both bitmaps container
+// by container the way And does, but only sums cardinalities instead of
+// building a result bitmap, so it does no heap allocation in the common
+// case.
+func (r *RoaringBitmapPosting) AndCardinality(o *RoaringBitmapPosting) uint64 {
+	if r.isEmpty() || o.isEmpty() {
+		return 0
+	}
+
+	var n uint64
+	ri, oi := 0, 0
+	for ri < len(r.keys) && oi < len(o.keys) {
+		switch {
+		case r.keys[ri] < o.keys[oi]:
+			ri++
+		case r.keys[ri] > o.keys[oi]:
+			oi++
+		default:
+			rc, oc := r.containers[ri], o.containers[oi]
+			// Walk whichever side is smaller with toSlice and test
+			// membership against the other, rather than building an
+			// intermediate "and" container just to count it.
+			small, big := rc, oc
+			if small.cardinality() > big.cardinality() {
+				small, big = big, small
+			}
+			for _, v := range small.toSlice() {
+				if big.contains(v) {
+					n++
+				}
+			}
+			ri++
+			oi++
+		}
+	}
+	return n
+}
@@ -0,0 +1,48 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_AndCardinality(t *testing.T) {
+	a := buildBitmap(1, 2, 70000, 70001)
+	b := buildBitmap(2, 3, 70000, 140000)
+
+	testutil.Equals(t, uint64(2), a.AndCardinality(b))
+}
+
+func TestRoaringBitmapPosting_AndCardinality_Empty(t *testing.T) {
+	a := buildBitmap(1, 2, 3)
+	b := NewRoaringBitmapPosting()
+
+	testutil.Equals(t, uint64(0), a.AndCardinality(b))
+	testutil.Equals(t, uint64(0), b.AndCardinality(a))
+}
+
+func BenchmarkRoaringBitmapPosting_AndCardinality(b *testing.B) {
+	x := NewRoaringBitmapPosting()
+	y := NewRoaringBitmapPosting()
+	x.AddRange(0, 1000000)
+	y.AddRange(500000, 1500000)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		x.AndCardinality(y)
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// AndNot returns a new bitmap holding every member of r that is not also a
+// member of o, leaving both r and o unmodified. It is the method form of
+// the package-level AndNot function, for callers holding a
+// *RoaringBitmapPosting who want a concrete difference bitmap to persist
+// rather than a streaming Without iterator.
+func (r *RoaringBitmapPosting) AndNot(o *RoaringBitmapPosting) *RoaringBitmapPosting {
+	return AndNot(r, o)
+}
@@ -0,0 +1,42 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_AndNot_Method(t *testing.T) {
+	a := buildBitmap(1, 2, 70000, 70001)
+	b := buildBitmap(2)
+
+	got := a.AndNot(b)
+	testutil.Equals(t, []uint64{1, 70000, 70001}, iterateAll(got))
+	testutil.Equals(t, []uint64{1, 2, 70000, 70001}, iterateAll(a))
+	testutil.Equals(t, []uint64{2}, iterateAll(b))
+}
+
+func TestRoaringBitmapPosting_AndNot_DropsEmptyContainer(t *testing.T) {
+	a := buildBitmap(1, 70000, 70001)
+	b := buildBitmap(70000, 70001)
+
+	got := a.AndNot(b)
+	testutil.Equals(t, []uint64{1}, iterateAll(got))
+	testutil.Assert(t, got.isEmpty() == false, "expected result to keep the unaffected container")
+
+	emptied := a.AndNot(buildBitmap(1, 70000, 70001))
+	testutil.Assert(t, emptied.isEmpty(), "expected subtracting everything to leave an empty bitmap")
+}
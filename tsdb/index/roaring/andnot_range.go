@@ -0,0 +1,34 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// AndNotRange removes every member in [lo, hi) in place and returns r, so
+// a tombstoning pass can chain a series of window subtractions. It is an
+// alias for RemoveRange with a chainable return value.
+func (r *RoaringBitmapPosting) AndNotRange(lo, hi uint64) *RoaringBitmapPosting {
+	r.RemoveRange(lo, hi)
+	return r
+}
+
+// AndNotRangeCopy returns a new bitmap holding every member of r outside
+// [lo, hi), leaving r unmodified.
+func (r *RoaringBitmapPosting) AndNotRangeCopy(lo, hi uint64) *RoaringBitmapPosting {
+	dst := NewRoaringBitmapPosting()
+	for i, key := range r.keys {
+		dst.keys = append(dst.keys, key)
+		dst.containers = append(dst.containers, r.containers[i].clone())
+	}
+	dst.RemoveRange(lo, hi)
+	return dst
+}
@@ -0,0 +1,36 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_AndNotRange(t *testing.T) {
+	r := buildBitmap(1, 2, 3, 4, 5)
+	got := r.AndNotRange(2, 4)
+	testutil.Equals(t, []uint64{1, 4, 5}, iterateAll(got))
+	testutil.Equals(t, []uint64{1, 4, 5}, iterateAll(r))
+}
+
+func TestRoaringBitmapPosting_AndNotRangeCopy(t *testing.T) {
+	r := buildBitmap(1, 2, 3, 4, 5)
+	got := r.AndNotRangeCopy(2, 4)
+
+	testutil.Equals(t, []uint64{1, 4, 5}, iterateAll(got))
+	// The source must be untouched.
+	testutil.Equals(t, []uint64{1, 2, 3, 4, 5}, iterateAll(r))
+}
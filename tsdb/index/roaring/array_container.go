@@ -1 +1,118 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
 package roaring
+
+import "sort"
+
+// arrayContainer stores members as a sorted slice of uint16. It is the
+// natural representation for sparse containers.
+type arrayContainer struct {
+	vals []uint16
+}
+
+func newArrayContainer() *arrayContainer {
+	return &arrayContainer{}
+}
+
+func (c *arrayContainer) typ() ContainerType { return ContainerArray }
+
+func (c *arrayContainer) find(v uint16) (int, bool) {
+	i := sort.Search(len(c.vals), func(i int) bool { return c.vals[i] >= v })
+	return i, i < len(c.vals) && c.vals[i] == v
+}
+
+func (c *arrayContainer) add(v uint16) container {
+	i, ok := c.find(v)
+	if ok {
+		return c
+	}
+	c.vals = append(c.vals, 0)
+	copy(c.vals[i+1:], c.vals[i:])
+	c.vals[i] = v
+	return c
+}
+
+func (c *arrayContainer) remove(v uint16) container {
+	i, ok := c.find(v)
+	if !ok {
+		return c
+	}
+	c.vals = append(c.vals[:i], c.vals[i+1:]...)
+	return c
+}
+
+func (c *arrayContainer) contains(v uint16) bool {
+	_, ok := c.find(v)
+	return ok
+}
+
+func (c *arrayContainer) cardinality() int { return len(c.vals) }
+
+func (c *arrayContainer) minimum() uint16 { return c.vals[0] }
+
+func (c *arrayContainer) maximum() uint16 { return c.vals[len(c.vals)-1] }
+
+func (c *arrayContainer) rankLE(v uint16) int {
+	i := sort.Search(len(c.vals), func(i int) bool { return c.vals[i] > v })
+	return i
+}
+
+func (c *arrayContainer) selectAt(i int) (uint16, bool) {
+	if i < 0 || i >= len(c.vals) {
+		return 0, false
+	}
+	return c.vals[i], true
+}
+
+func (c *arrayContainer) clone() container {
+	vals := make([]uint16, len(c.vals))
+	copy(vals, c.vals)
+	return &arrayContainer{vals: vals}
+}
+
+func (c *arrayContainer) and(o container) container {
+	res := newArrayContainer()
+	for _, v := range c.vals {
+		if o.contains(v) {
+			res.vals = append(res.vals, v)
+		}
+	}
+	return res
+}
+
+func (c *arrayContainer) or(o container) container {
+	res := c.clone()
+	for _, v := range o.toSlice() {
+		res = res.add(v)
+	}
+	return res
+}
+
+func (c *arrayContainer) andNot(o container) container {
+	res := newArrayContainer()
+	for _, v := range c.vals {
+		if !o.contains(v) {
+			res.vals = append(res.vals, v)
+		}
+	}
+	return res
+}
+
+func (c *arrayContainer) sizeBytes() uint64 {
+	return uint64(len(c.vals)) * 2
+}
+
+func (c *arrayContainer) toSlice() []uint16 {
+	return c.vals
+}
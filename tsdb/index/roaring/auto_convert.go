@@ -0,0 +1,48 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// ArrayMaxCardinality is the cardinality above which Add converts a
+// container from array to bitmap representation, and at or below which
+// Remove converts a bitmap container back to array. 4096 is the classic
+// roaring-bitmap crossover point: above it, a 2-byte-per-value array is
+// larger than the fixed 8KB bitmap. It is a package-level var so callers
+// can tune the tradeoff; changing it only affects containers touched by a
+// later Add/Remove, not ones already converted.
+var ArrayMaxCardinality = 4096
+
+// growIfDense converts c from array to bitmap representation once its
+// cardinality exceeds ArrayMaxCardinality. Run containers are left alone;
+// RunOptimize/Compact are the explicit entry points for run selection.
+func growIfDense(c container) container {
+	if c.typ() != ContainerArray || c.cardinality() <= ArrayMaxCardinality {
+		return c
+	}
+	bmp := newBitmapContainer()
+	for _, v := range c.toSlice() {
+		bmp.add(v)
+	}
+	return bmp
+}
+
+// shrinkIfSparse converts c from bitmap to array representation once its
+// cardinality drops to ArrayMaxCardinality or below.
+func shrinkIfSparse(c container) container {
+	if c.typ() != ContainerBitmap || c.cardinality() > ArrayMaxCardinality {
+		return c
+	}
+	arr := newArrayContainer()
+	arr.vals = append(arr.vals, c.toSlice()...)
+	return arr
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_AutoConvertsArrayToBitmap(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	for v := uint64(0); v < uint64(ArrayMaxCardinality); v++ {
+		r.Add(v)
+		testutil.Equals(t, ContainerArray, r.ContainerStats()[0].Type)
+	}
+
+	r.Add(uint64(ArrayMaxCardinality))
+	testutil.Equals(t, ContainerBitmap, r.ContainerStats()[0].Type)
+}
+
+func TestRoaringBitmapPosting_AutoConvertsBitmapBackToArray(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	for v := uint64(0); v < uint64(ArrayMaxCardinality)+2; v++ {
+		r.Add(v)
+	}
+	testutil.Equals(t, ContainerBitmap, r.ContainerStats()[0].Type)
+
+	r.Remove(uint64(ArrayMaxCardinality) + 1)
+	testutil.Equals(t, ContainerBitmap, r.ContainerStats()[0].Type)
+
+	r.Remove(uint64(ArrayMaxCardinality))
+	testutil.Equals(t, ContainerArray, r.ContainerStats()[0].Type)
+}
+
+func TestRoaringBitmapPosting_AutoConvertIsTransparent(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	for v := uint64(0); v < 30000; v += 2 {
+		r.Add(v)
+	}
+	testutil.Equals(t, ContainerBitmap, r.ContainerStats()[0].Type)
+
+	for v := uint64(0); v < 30000; v++ {
+		testutil.Equals(t, v%2 == 0, r.Contains(v))
+	}
+	testutil.Assert(t, !r.Contains(30000), "expected a value past the populated range to be absent")
+	testutil.Equals(t, uint64(15000), r.Cardinality())
+
+	var n int
+	for it := NewRoaringBitmapIterator(r); it.Next(); {
+		n++
+	}
+	testutil.Equals(t, 15000, n)
+}
+
+// BenchmarkArrayVsBitmap_30000DenseElements reports the in-memory size of a
+// single container holding 30000 dense (contiguous) elements under array vs
+// bitmap representation, to justify auto-converting past ArrayMaxCardinality.
+func BenchmarkArrayVsBitmap_30000DenseElements(b *testing.B) {
+	const n = 30000
+
+	b.Run("array", func(b *testing.B) {
+		arr := newArrayContainer()
+		for v := uint16(0); v < n; v++ {
+			arr.vals = append(arr.vals, v)
+		}
+		b.ReportMetric(float64(arr.sizeBytes()), "bytes")
+	})
+	b.Run("bitmap", func(b *testing.B) {
+		bmp := newBitmapContainer()
+		for v := uint16(0); v < n; v++ {
+			bmp.add(v)
+		}
+		b.ReportMetric(float64(bmp.sizeBytes()), "bytes")
+	})
+}
@@ -1 +1,165 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
 package roaring
+
+import "math/bits"
+
+// bitmapWords is the number of uint64 words needed to address all 65536
+// positions within a container.
+const bitmapWords = 1 << 16 / 64
+
+// bitmapContainer stores members as a fixed 65536-bit bitset. It is the
+// natural representation for dense containers.
+type bitmapContainer struct {
+	words [bitmapWords]uint64
+	card  int
+}
+
+func newBitmapContainer() *bitmapContainer {
+	return &bitmapContainer{}
+}
+
+func (c *bitmapContainer) typ() ContainerType { return ContainerBitmap }
+
+func (c *bitmapContainer) add(v uint16) container {
+	word, bit := v/64, v%64
+	mask := uint64(1) << bit
+	if c.words[word]&mask == 0 {
+		c.words[word] |= mask
+		c.card++
+	}
+	return c
+}
+
+func (c *bitmapContainer) remove(v uint16) container {
+	word, bit := v/64, v%64
+	mask := uint64(1) << bit
+	if c.words[word]&mask != 0 {
+		c.words[word] &^= mask
+		c.card--
+	}
+	return c
+}
+
+func (c *bitmapContainer) contains(v uint16) bool {
+	word, bit := v/64, v%64
+	return c.words[word]&(uint64(1)<<bit) != 0
+}
+
+func (c *bitmapContainer) cardinality() int { return c.card }
+
+func (c *bitmapContainer) minimum() uint16 {
+	for i, w := range c.words {
+		if w != 0 {
+			return uint16(i*64 + bits.TrailingZeros64(w))
+		}
+	}
+	return 0
+}
+
+func (c *bitmapContainer) maximum() uint16 {
+	for i := len(c.words) - 1; i >= 0; i-- {
+		if c.words[i] != 0 {
+			return uint16(i*64 + 63 - bits.LeadingZeros64(c.words[i]))
+		}
+	}
+	return 0
+}
+
+func (c *bitmapContainer) rankLE(v uint16) int {
+	word := int(v) / 64
+	rank := 0
+	for i := 0; i < word; i++ {
+		rank += bits.OnesCount64(c.words[i])
+	}
+	bit := v % 64
+	mask := uint64(1)<<(bit+1) - 1
+	if bit == 63 {
+		mask = ^uint64(0)
+	}
+	rank += bits.OnesCount64(c.words[word] & mask)
+	return rank
+}
+
+func (c *bitmapContainer) selectAt(i int) (uint16, bool) {
+	if i < 0 || i >= c.card {
+		return 0, false
+	}
+	remaining := i
+	for wi, w := range c.words {
+		cnt := bits.OnesCount64(w)
+		if remaining >= cnt {
+			remaining -= cnt
+			continue
+		}
+		for w != 0 {
+			bit := bits.TrailingZeros64(w)
+			if remaining == 0 {
+				return uint16(wi*64 + bit), true
+			}
+			remaining--
+			w &^= uint64(1) << bit
+		}
+	}
+	return 0, false
+}
+
+func (c *bitmapContainer) clone() container {
+	cl := &bitmapContainer{words: c.words, card: c.card}
+	return cl
+}
+
+func (c *bitmapContainer) and(o container) container {
+	res := newArrayContainer()
+	for _, v := range c.toSlice() {
+		if o.contains(v) {
+			res.vals = append(res.vals, v)
+		}
+	}
+	return res
+}
+
+func (c *bitmapContainer) or(o container) container {
+	res := c.clone().(*bitmapContainer)
+	for _, v := range o.toSlice() {
+		res.add(v)
+	}
+	return res
+}
+
+func (c *bitmapContainer) andNot(o container) container {
+	res := newArrayContainer()
+	for _, v := range c.toSlice() {
+		if !o.contains(v) {
+			res.vals = append(res.vals, v)
+		}
+	}
+	return res
+}
+
+func (c *bitmapContainer) sizeBytes() uint64 {
+	return uint64(len(c.words)) * 8
+}
+
+func (c *bitmapContainer) toSlice() []uint16 {
+	vals := make([]uint16, 0, c.card)
+	for wi, w := range c.words {
+		for w != 0 {
+			bit := bits.TrailingZeros64(w)
+			vals = append(vals, uint16(wi*64+bit))
+			w &^= uint64(1) << bit
+		}
+	}
+	return vals
+}
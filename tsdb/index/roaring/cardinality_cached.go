@@ -0,0 +1,110 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// AddRange inserts every ID in [lo, hi) into the bitmap. Rather than calling
+// Add once per ID, each covered container is filled by ORing in a single
+// run spanning that container's slice of the range, so the cost is
+// proportional to the number of containers touched, not the number of IDs.
+func (r *RoaringBitmapPosting) AddRange(lo, hi uint64) {
+	r.checkMutable()
+	if hi <= lo {
+		return
+	}
+	r.cardCacheValid = false
+	r.treeCacheValid = false
+
+	last := hi - 1
+	startKey, endKey := highBits(lo), highBits(last)
+	for key := startKey; key <= endKey; key++ {
+		lo16, hi16 := uint16(0), uint16(0xffff)
+		if key == startKey {
+			lo16 = lowBits(lo)
+		}
+		if key == endKey {
+			hi16 = lowBits(last)
+		}
+		fill := newRunContainer()
+		fill.runs = []run{{start: lo16, length: hi16 - lo16}}
+
+		i := r.containerFor(key)
+		r.containers[i] = r.containers[i].or(fill)
+		if key == endKey {
+			break
+		}
+	}
+}
+
+// RemoveRange deletes every ID in [lo, hi) from the bitmap. Containers that
+// fall entirely inside the range are dropped wholesale; only the (at most
+// two) boundary containers need per-member filtering.
+func (r *RoaringBitmapPosting) RemoveRange(lo, hi uint64) {
+	r.checkMutable()
+	if hi <= lo {
+		return
+	}
+	r.cardCacheValid = false
+	r.treeCacheValid = false
+
+	loKey, hiKey := highBits(lo), highBits(hi-1)
+	keys := r.keys[:0]
+	containers := r.containers[:0]
+	for i, key := range r.keys {
+		switch {
+		case key < loKey || key > hiKey:
+			keys = append(keys, key)
+			containers = append(containers, r.containers[i])
+		case key > loKey && key < hiKey:
+			continue
+		default:
+			c := r.containers[i]
+			vals := append([]uint16(nil), c.toSlice()...)
+			for _, v := range vals {
+				id := joinBits(key, v)
+				if id >= lo && id < hi {
+					c = c.remove(v)
+				}
+			}
+			if c.cardinality() > 0 {
+				keys = append(keys, key)
+				containers = append(containers, c)
+			}
+		}
+	}
+	r.keys = keys
+	r.containers = containers
+}
+
+// Clear empties the bitmap but keeps the underlying keys/containers slices
+// at their current capacity, so a cleared bitmap can be reused for the next
+// batch without a fresh allocation.
+func (r *RoaringBitmapPosting) Clear() {
+	r.checkMutable()
+	r.cardCacheValid = false
+	r.treeCacheValid = false
+	r.keys = r.keys[:0]
+	r.containers = r.containers[:0]
+}
+
+// CardinalityCached returns the same value as Cardinality, but remembers it
+// across calls so that repeated calls between mutations are O(1) instead of
+// re-summing every container. The cache is invalidated by Add, Remove,
+// AddRange, RemoveRange, and Clear.
+func (r *RoaringBitmapPosting) CardinalityCached() uint64 {
+	if !r.cardCacheValid {
+		r.cardCache = r.Cardinality()
+		r.cardCacheValid = true
+	}
+	return r.cardCache
+}
@@ -0,0 +1,132 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_CardinalityCached(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	testutil.Equals(t, uint64(0), r.CardinalityCached())
+
+	r.Add(1)
+	r.Add(2)
+	testutil.Equals(t, uint64(2), r.CardinalityCached())
+	testutil.Equals(t, uint64(2), r.CardinalityCached())
+
+	r.Remove(1)
+	testutil.Equals(t, uint64(1), r.CardinalityCached())
+
+	r.AddRange(10, 20)
+	testutil.Equals(t, uint64(11), r.CardinalityCached())
+
+	r.RemoveRange(10, 15)
+	testutil.Equals(t, uint64(6), r.CardinalityCached())
+
+	r.Clear()
+	testutil.Equals(t, uint64(0), r.CardinalityCached())
+}
+
+func TestRoaringBitmapPosting_AddRangeRemoveRange(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	r.AddRange(70000, 70005)
+	testutil.Equals(t, []uint64{70000, 70001, 70002, 70003, 70004}, iterateAll(r))
+
+	r.RemoveRange(70001, 70003)
+	testutil.Equals(t, []uint64{70000, 70003, 70004}, iterateAll(r))
+}
+
+func TestRemoveRange_MiddleThirdOfDenseRange(t *testing.T) {
+	const n = 90000
+	r := NewRoaringRange(0, n)
+
+	third := uint64(n / 3)
+	r.RemoveRange(third, 2*third)
+
+	got := iterateAll(r)
+	want := make([]uint64, 0, n-int(third))
+	for v := uint64(0); v < third; v++ {
+		want = append(want, v)
+	}
+	for v := 2 * third; v < n; v++ {
+		want = append(want, v)
+	}
+	testutil.Equals(t, want, got)
+	testutil.Equals(t, uint64(len(want)), r.Cardinality())
+}
+
+func BenchmarkRoaringBitmapPosting_CardinalityCached(b *testing.B) {
+	r := NewRoaringBitmapPosting()
+	r.AddRange(0, 1000000)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.CardinalityCached()
+	}
+}
+
+func BenchmarkAddRange_OneMillion(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		r := NewRoaringBitmapPosting()
+		r.AddRange(0, 1000000)
+	}
+}
+
+func TestClear_RetainsCapacity(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	for v := uint64(0); v < 100; v++ {
+		r.Add(v * 70000)
+	}
+	capBefore := cap(r.containers)
+
+	r.Clear()
+	testutil.Equals(t, uint64(0), r.Cardinality())
+	testutil.Assert(t, r.isEmpty(), "expected a cleared bitmap to be empty")
+	testutil.Equals(t, capBefore, cap(r.containers))
+}
+
+func BenchmarkBitmapReuse_ClearVsFresh(b *testing.B) {
+	b.Run("reuse", func(b *testing.B) {
+		r := NewRoaringBitmapPosting()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r.Clear()
+			for v := uint64(0); v < 100; v++ {
+				r.Add(v * 70000)
+			}
+		}
+	})
+	b.Run("fresh", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r := NewRoaringBitmapPosting()
+			for v := uint64(0); v < 100; v++ {
+				r.Add(v * 70000)
+			}
+		}
+	})
+}
+
+func BenchmarkAddRange_NaiveLoop_OneMillion(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		r := NewRoaringBitmapPosting()
+		for v := uint64(0); v < 1000000; v++ {
+			r.Add(v)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+// TestCardinality_StableAcrossNoOpMutations locks in that Cardinality keeps
+// tracking the true number of distinct members, even when Add is asked to
+// insert a value that is already present, or Remove is asked to drop a
+// value that was never there.
+func TestCardinality_StableAcrossNoOpMutations(t *testing.T) {
+	r := buildBitmap(1, 2, 70000)
+	testutil.Equals(t, uint64(3), r.Cardinality())
+
+	r.Add(2) // already present: no-op.
+	testutil.Equals(t, uint64(3), r.Cardinality())
+
+	r.Remove(999999) // never present: no-op.
+	testutil.Equals(t, uint64(3), r.Cardinality())
+
+	r.Remove(2)
+	testutil.Equals(t, uint64(2), r.Cardinality())
+
+	r.Remove(2) // already gone: no-op.
+	testutil.Equals(t, uint64(2), r.Cardinality())
+
+	r.Add(2)
+	testutil.Equals(t, uint64(3), r.Cardinality())
+}
@@ -0,0 +1,28 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// Clone returns a deep copy of r: every container is cloned so that
+// mutating either bitmap afterwards leaves the other untouched. The clone
+// starts unfrozen even if r is frozen.
+func (r *RoaringBitmapPosting) Clone() *RoaringBitmapPosting {
+	clone := &RoaringBitmapPosting{
+		keys:       append([]uint64(nil), r.keys...),
+		containers: make([]container, len(r.containers)),
+	}
+	for i, c := range r.containers {
+		clone.containers[i] = c.clone()
+	}
+	return clone
+}
@@ -0,0 +1,33 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestClone_DivergesAfterIndependentMutation(t *testing.T) {
+	orig := buildBitmap(1, 2, 70000)
+	clone := orig.Clone()
+	testutil.Equals(t, iterateAll(orig), iterateAll(clone))
+
+	orig.Add(3)
+	clone.Add(70001)
+	clone.Remove(2)
+
+	testutil.Equals(t, []uint64{1, 2, 3, 70000}, iterateAll(orig))
+	testutil.Equals(t, []uint64{1, 70000, 70001}, iterateAll(clone))
+}
@@ -0,0 +1,41 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import "fmt"
+
+// SizeBytes returns the total estimated in-memory size of the bitmap: its
+// per-container footprint, summed across whatever representation each one
+// currently uses, plus the index overhead of the keys slice that locates
+// them.
+func (r *RoaringBitmapPosting) SizeBytes() uint64 {
+	n := uint64(len(r.keys)) * 8
+	for _, c := range r.containers {
+		n += c.sizeBytes()
+	}
+	return n
+}
+
+// CompactTo tries to bring the bitmap under maxBytes by re-selecting the
+// smallest representation for every container, the same way Optimize does.
+// It returns an error without modifying membership if even the smallest
+// possible representation still exceeds maxBytes.
+func (r *RoaringBitmapPosting) CompactTo(maxBytes uint64) error {
+	r.checkMutable()
+	r.Optimize()
+	if size := r.SizeBytes(); size > maxBytes {
+		return fmt.Errorf("roaring: cannot compact bitmap to %d bytes, smallest representation uses %d bytes", maxBytes, size)
+	}
+	return nil
+}
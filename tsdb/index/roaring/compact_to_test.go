@@ -0,0 +1,42 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_CompactTo(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	for i := uint64(0); i < 1000; i++ {
+		r.Add(i * 2)
+	}
+
+	err := r.CompactTo(1 << 20)
+	testutil.Ok(t, err)
+	testutil.Equals(t, ContainerArray, r.ContainerStats()[0].Type)
+	testutil.Equals(t, 1000, r.ContainerStats()[0].Cardinality)
+}
+
+func TestRoaringBitmapPosting_CompactTo_TooSmall(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	for i := uint64(0); i < 1000; i++ {
+		r.Add(i * 2)
+	}
+
+	err := r.CompactTo(1)
+	testutil.NotOk(t, err)
+}
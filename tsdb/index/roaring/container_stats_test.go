@@ -0,0 +1,53 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+// TestRoaringBitmapPosting_ContainerStats_ReportsTypeByDensity builds one
+// intentionally sparse and one intentionally dense container and checks
+// that ContainerStats reports each one's actual representation, alongside
+// its high key, cardinality, and byte size.
+func TestRoaringBitmapPosting_ContainerStats_ReportsTypeByDensity(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+
+	// Sparse: 3 widely-spaced values in container 0, left as an array.
+	r.Add(1)
+	r.Add(1000)
+	r.Add(60000)
+
+	// Dense: every value in container 1, converted to a bitmap by Add's
+	// own array-to-bitmap threshold.
+	const denseStart = uint64(1) << 16
+	for i := uint64(0); i < 40000; i++ {
+		r.Add(denseStart + i)
+	}
+
+	stats := r.ContainerStats()
+	testutil.Equals(t, 2, len(stats))
+
+	testutil.Equals(t, uint64(0), stats[0].HighKey)
+	testutil.Equals(t, ContainerArray, stats[0].Type)
+	testutil.Equals(t, 3, stats[0].Cardinality)
+	testutil.Equals(t, uint64(6), stats[0].SizeBytes)
+
+	testutil.Equals(t, uint64(1), stats[1].HighKey)
+	testutil.Equals(t, ContainerBitmap, stats[1].Type)
+	testutil.Equals(t, 40000, stats[1].Cardinality)
+	testutil.Equals(t, uint64(bitmapWords)*8, stats[1].SizeBytes)
+}
@@ -1 +1,90 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
 package roaring
+
+import "sort"
+
+// ContainerType identifies the physical representation backing a single
+// high-key container of a RoaringBitmapPosting.
+type ContainerType uint8
+
+const (
+	// ContainerArray stores members as a sorted slice of low 16 bits. It is
+	// compact for sparse containers.
+	ContainerArray ContainerType = iota
+	// ContainerBitmap stores members as a fixed-size bitset. It is efficient
+	// for dense containers.
+	ContainerBitmap
+	// ContainerRun stores members as a list of (start, length) runs. It is
+	// efficient for long contiguous ranges.
+	ContainerRun
+)
+
+func (t ContainerType) String() string {
+	switch t {
+	case ContainerArray:
+		return "array"
+	case ContainerBitmap:
+		return "bitmap"
+	case ContainerRun:
+		return "run"
+	default:
+		return "unknown"
+	}
+}
+
+// container is the behaviour every container representation must provide.
+// All methods operate on the 16 low bits of an ID; the high bits are tracked
+// by RoaringBitmapPosting alongside the container.
+type container interface {
+	typ() ContainerType
+	add(v uint16) container
+	remove(v uint16) container
+	contains(v uint16) bool
+	cardinality() int
+	minimum() uint16
+	maximum() uint16
+	rankLE(v uint16) int
+	selectAt(i int) (uint16, bool)
+	clone() container
+	and(o container) container
+	or(o container) container
+	andNot(o container) container
+	sizeBytes() uint64
+	toSlice() []uint16
+}
+
+// highBits returns the container key (the ID's bits above the low 16).
+func highBits(v uint64) uint64 {
+	return v >> 16
+}
+
+// lowBits returns the ID's position within its container.
+func lowBits(v uint64) uint16 {
+	return uint16(v & 0xffff)
+}
+
+// joinBits reconstructs a full ID from a container key and a low offset.
+func joinBits(key uint64, low uint16) uint64 {
+	return key<<16 | uint64(low)
+}
+
+// search returns the index of key in keys, and whether it was found.
+func search(keys []uint64, key uint64) (int, bool) {
+	i := sort.Search(len(keys), func(i int) bool { return keys[i] >= key })
+	if i < len(keys) && keys[i] == key {
+		return i, true
+	}
+	return i, false
+}
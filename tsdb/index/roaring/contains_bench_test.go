@@ -0,0 +1,48 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import "testing"
+
+// seekContains answers the same question as Contains, but by driving a fresh
+// iterator with Seek rather than doing a direct container lookup. It exists
+// only to give Contains a baseline to be benchmarked against.
+func seekContains(r *RoaringBitmapPosting, v uint64) bool {
+	it := NewRoaringBitmapIterator(r)
+	return it.Seek(v) && it.At() == v
+}
+
+func benchmarkBitmap10M() *RoaringBitmapPosting {
+	r := NewRoaringBitmapPosting()
+	for i := uint64(0); i < 10_000_000; i += 2 {
+		r.Add(i)
+	}
+	return r
+}
+
+func BenchmarkContains_DirectLookup(b *testing.B) {
+	r := benchmarkBitmap10M()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = r.Contains(uint64(i) % 10_000_000)
+	}
+}
+
+func BenchmarkContains_ViaSeek(b *testing.B) {
+	r := benchmarkBitmap10M()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = seekContains(r, uint64(i)%10_000_000)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// AndNotCardinality returns the number of members of r that are not members
+// of other, i.e. |r \ other|, without materializing the difference.
+func (r *RoaringBitmapPosting) AndNotCardinality(other *RoaringBitmapPosting) uint64 {
+	var n uint64
+	for i, key := range r.keys {
+		oi, ok := search(other.keys, key)
+		if !ok {
+			n += uint64(r.containers[i].cardinality())
+			continue
+		}
+		for _, v := range r.containers[i].toSlice() {
+			if !other.containers[oi].contains(v) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Diff summarizes how r and other differ: addedCount is |other \ r|, the
+// members present in other but not r, and removedCount is |r \ other|, the
+// members present in r but not other. It is meant for audit logs that only
+// need the size of the change, not the changed IDs themselves.
+func (r *RoaringBitmapPosting) Diff(other *RoaringBitmapPosting) (addedCount, removedCount uint64) {
+	return other.AndNotCardinality(r), r.AndNotCardinality(other)
+}
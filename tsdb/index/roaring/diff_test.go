@@ -0,0 +1,36 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_Diff(t *testing.T) {
+	a := NewRoaringBitmapPosting()
+	for _, v := range []uint64{1, 2, 3, 1 << 16} {
+		a.Add(v)
+	}
+
+	b := NewRoaringBitmapPosting()
+	for _, v := range []uint64{2, 3, 4, 1 << 16, (1 << 16) + 1} {
+		b.Add(v)
+	}
+
+	added, removed := a.Diff(b)
+	testutil.Equals(t, uint64(2), added)   // 4, (1<<16)+1
+	testutil.Equals(t, uint64(1), removed) // 1
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import "sync"
+
+// EachParallel invokes fn once for every member of r, distributing whole
+// containers across workers goroutines. fn must be safe for concurrent
+// calls: it may be called from any worker and IDs from different
+// containers may be delivered concurrently, though IDs within a single
+// container are always delivered in order on the same goroutine.
+//
+// If workers is less than 1, a single worker is used.
+func (r *RoaringBitmapPosting) EachParallel(workers int, fn func(uint64)) {
+	if workers < 1 {
+		workers = 1
+	}
+	if len(r.containers) == 0 {
+		return
+	}
+	if workers > len(r.containers) {
+		workers = len(r.containers)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				key := r.keys[i]
+				for _, v := range r.containers[i].toSlice() {
+					fn(joinBits(key, v))
+				}
+			}
+		}()
+	}
+	for i := range r.containers {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
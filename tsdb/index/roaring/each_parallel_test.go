@@ -0,0 +1,39 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_EachParallel(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	for i := uint64(0); i < 5000; i++ {
+		r.Add(i * 3)
+	}
+	// Spread members across several containers.
+	r.Add(1 << 17)
+	r.Add((1 << 17) + 5)
+	r.Add(1 << 18)
+
+	var count int64
+	r.EachParallel(4, func(uint64) {
+		atomic.AddInt64(&count, 1)
+	})
+
+	testutil.Equals(t, int64(r.Cardinality()), count)
+}
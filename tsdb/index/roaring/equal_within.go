@@ -0,0 +1,24 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// EqualWithin reports whether r and other differ by at most maxDiff
+// members, i.e. the symmetric difference |r \ other| + |other \ r| is no
+// greater than maxDiff. It is meant for asserting approximate equality in
+// fuzz tests that compare a bitmap against a lossy re-encoding of itself.
+func (r *RoaringBitmapPosting) EqualWithin(other *RoaringBitmapPosting, maxDiff uint64) bool {
+	removed := r.AndNotCardinality(other)
+	added := other.AndNotCardinality(r)
+	return removed+added <= maxDiff
+}
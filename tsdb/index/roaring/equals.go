@@ -0,0 +1,41 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// Equals reports whether r and o hold exactly the same members. It walks
+// containers key-by-key rather than building Postings iterators for both
+// sides, and compares each matching pair's members via toSlice so that two
+// containers with different representations (e.g. array vs run) but
+// identical membership still compare equal.
+func (r *RoaringBitmapPosting) Equals(o *RoaringBitmapPosting) bool {
+	if len(r.keys) != len(o.keys) {
+		return false
+	}
+	for i, key := range r.keys {
+		if o.keys[i] != key {
+			return false
+		}
+		a, b := r.containers[i], o.containers[i]
+		if a.cardinality() != b.cardinality() {
+			return false
+		}
+		av, bv := a.toSlice(), b.toSlice()
+		for j := range av {
+			if av[j] != bv[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
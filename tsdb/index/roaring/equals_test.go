@@ -0,0 +1,47 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestEquals_EmptyVsEmpty(t *testing.T) {
+	testutil.Assert(t, NewRoaringBitmapPosting().Equals(NewRoaringBitmapPosting()), "two empty bitmaps should be equal")
+}
+
+func TestEquals_DifferingHighKeys(t *testing.T) {
+	a := buildBitmap(1, 70000)
+	b := buildBitmap(1, 140000)
+	testutil.Assert(t, !a.Equals(b), "bitmaps with different high keys should not be equal")
+}
+
+// TestEquals_IgnoresContainerRepresentation confirms an array container and
+// a run container holding the same members still compare equal.
+func TestEquals_IgnoresContainerRepresentation(t *testing.T) {
+	array := buildBitmap(1, 2, 3, 4, 5)
+
+	run := NewRoaringRange(1, 6)
+
+	testutil.Assert(t, array.Equals(run), "array and run containers with identical membership should be equal")
+	testutil.Assert(t, run.Equals(array), "Equals should be symmetric")
+}
+
+func TestEquals_DifferentCardinality(t *testing.T) {
+	a := buildBitmap(1, 2, 3)
+	b := buildBitmap(1, 2)
+	testutil.Assert(t, !a.Equals(b), "bitmaps with different cardinality should not be equal")
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// Freeze marks r as immutable and returns a read-only view over it, so it
+// can be shared across goroutines without cloning on every read. r itself
+// keeps panicking on mutation once frozen (see checkMutable), but
+// FrozenRoaringBitmap has no Add/Remove methods to call in the first
+// place, so misuse through the view is a compile error rather than a
+// runtime panic. Contains, Cardinality, Rank, and iteration are all safe
+// for concurrent use once frozen.
+//
+// Contains resolves its container through r.treeCache, built lazily and
+// invalidated on mutation - neither of which is safe for concurrent callers
+// to race on. Freeze builds the cache once, up front, while r is still
+// only reachable from the calling goroutine; since a frozen r never mutates
+// again, nothing ever invalidates it afterwards, so concurrent callers only
+// ever read it.
+func (r *RoaringBitmapPosting) Freeze() *FrozenRoaringBitmap {
+	r.containerTreeCached()
+	r.frozen = true
+	return &FrozenRoaringBitmap{r: r}
+}
+
+// checkMutable panics if r has been frozen. It is called at the top of
+// every method that mutates r in place.
+func (r *RoaringBitmapPosting) checkMutable() {
+	if r.frozen {
+		panic("roaring: mutation attempted on a frozen RoaringBitmapPosting")
+	}
+}
+
+// FrozenRoaringBitmap is a read-only view over a RoaringBitmapPosting that
+// has been Frozen. It forwards to the read-only subset of the underlying
+// bitmap's methods.
+type FrozenRoaringBitmap struct {
+	r *RoaringBitmapPosting
+}
+
+// Contains reports whether v is a member of the bitmap.
+func (f *FrozenRoaringBitmap) Contains(v uint64) bool {
+	return f.r.Contains(v)
+}
+
+// Cardinality returns the number of members in the bitmap.
+func (f *FrozenRoaringBitmap) Cardinality() uint64 {
+	return f.r.Cardinality()
+}
+
+// Rank returns the number of members of the bitmap that are <= x.
+func (f *FrozenRoaringBitmap) Rank(x uint64) uint64 {
+	return f.r.Rank(x)
+}
+
+// Iterator returns a forward iterator over the bitmap's members.
+func (f *FrozenRoaringBitmap) Iterator() *roaringBitmapIterator {
+	return NewRoaringBitmapIterator(f.r)
+}
@@ -0,0 +1,90 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_Freeze_PanicsOnMutation(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	r.Add(1)
+	r.Add(2)
+	r.Freeze()
+
+	testutil.Assert(t, panics(func() { r.Add(3) }), "expected Add to panic on a frozen bitmap")
+	testutil.Assert(t, panics(func() { r.Remove(1) }), "expected Remove to panic on a frozen bitmap")
+}
+
+func panics(f func()) (didPanic bool) {
+	defer func() {
+		if recover() != nil {
+			didPanic = true
+		}
+	}()
+	f()
+	return false
+}
+
+func TestRoaringBitmapPosting_Freeze_ConcurrentIteration(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	for i := uint64(0); i < 5000; i++ {
+		r.Add(i * 3)
+	}
+	frozen := r.Freeze()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			it := frozen.Iterator()
+			for it.Next() {
+				frozen.Contains(it.At())
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestFrozenRoaringBitmap_MatchesUnderlying checks that every read
+// exposed through the frozen view agrees with the same read made directly
+// against the underlying bitmap. FrozenRoaringBitmap having no Add/Remove
+// methods at all (so calling them would be a compile error, not something
+// this test can exercise) is the other half of Freeze's contract.
+func TestFrozenRoaringBitmap_MatchesUnderlying(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	for i := uint64(0); i < 5000; i += 3 {
+		r.Add(i)
+	}
+	frozen := r.Freeze()
+
+	testutil.Equals(t, r.Cardinality(), frozen.Cardinality())
+	testutil.Equals(t, r.Rank(2500), frozen.Rank(2500))
+	for _, v := range []uint64{0, 3, 4, 4998, 5000} {
+		testutil.Equals(t, r.Contains(v), frozen.Contains(v))
+	}
+
+	var viaFrozen, viaUnderlying []uint64
+	for it := frozen.Iterator(); it.Next(); {
+		viaFrozen = append(viaFrozen, it.At())
+	}
+	for it := NewRoaringBitmapIterator(r); it.Next(); {
+		viaUnderlying = append(viaUnderlying, it.At())
+	}
+	testutil.Equals(t, viaUnderlying, viaFrozen)
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Only build when go-fuzz is in use
+//go:build gofuzz
+// +build gofuzz
+
+package roaring
+
+// Radix tree fuzzing instrumentation for use with
+// https://github.com/dvyukov/go-fuzz.
+//
+//	go-fuzz-build -func FuzzTree -o FuzzTree.zip github.com/prometheus/prometheus/tsdb/index/roaring
+//	go-fuzz -bin FuzzTree.zip -workdir fuzz-data/Tree
+//
+// FuzzTree replays the input as a sequence of insert/find operations against
+// both the radix tree and a reference map[string]*T, failing (via panic) the
+// moment the two disagree. This is the kind of check that would have caught
+// the node-copy bug in _find (root = &i instead of &root.child[idx]).
+func FuzzTree(data []byte) int {
+	if len(data) < 2 {
+		return 0
+	}
+
+	tr := &tree{}
+	ref := map[string]*T{}
+
+	i := 0
+	next := func() byte {
+		if i >= len(data) {
+			i = 0
+		}
+		b := data[i]
+		i++
+		return b
+	}
+
+	for i < len(data) {
+		op := next() % 2
+		n := int(next()%8) + 1
+		if i+n > len(data) {
+			n = len(data) - i
+		}
+		key := data[i : i+n]
+		i += n
+
+		switch op {
+		case 0: // insert
+			c := newArrayContainer()
+			c.vals = []uint16{uint16(len(ref) % 65536)}
+			var v T = c
+			tr.insert(key, &v)
+			ref[string(key)] = &v
+		case 1: // find
+			got := tr.find(key)
+			want := ref[string(key)]
+			if (got == nil) != (want == nil) {
+				panic("find disagrees with reference map on presence")
+			}
+			if got != nil && *got != *want {
+				panic("find disagrees with reference map on value")
+			}
+		}
+	}
+
+	return 1
+}
@@ -0,0 +1,25 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// HighBitsKeys returns the sorted container keys present in the bitmap, so
+// callers can route whole containers to shards by high bits without
+// iterating individual IDs. Keys are uint64, not uint16: a container only
+// covers the low 16 bits of an ID, so the high-bits space here spans the
+// remaining 48 bits, not a 16-bit range.
+func (r *RoaringBitmapPosting) HighBitsKeys() []uint64 {
+	keys := make([]uint64, len(r.keys))
+	copy(keys, r.keys)
+	return keys
+}
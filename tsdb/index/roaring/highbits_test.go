@@ -0,0 +1,29 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_HighBitsKeys(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	r.Add(1)           // container 0
+	r.Add(2<<16 + 5)   // container 2
+	r.Add(5<<16 + 100) // container 5
+
+	testutil.Equals(t, []uint64{0, 2, 5}, r.HighBitsKeys())
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// IntersectManyInto computes the intersection of bms into dst, reusing
+// dst's backing storage across calls instead of allocating a fresh result
+// bitmap each time. It starts from a clone of the smallest input (fewest
+// containers is a cheap proxy for smallest cardinality) and And's the rest
+// in, which keeps the working set small for a query executor that runs
+// many intersections per request.
+func IntersectManyInto(dst *RoaringBitmapPosting, bms ...*RoaringBitmapPosting) {
+	dst.checkMutable()
+	dst.cardCacheValid = false
+	dst.treeCacheValid = false
+	dst.keys = dst.keys[:0]
+	dst.containers = dst.containers[:0]
+
+	if len(bms) == 0 {
+		return
+	}
+
+	smallest := bms[0]
+	for _, bm := range bms[1:] {
+		if len(bm.containers) < len(smallest.containers) {
+			smallest = bm
+		}
+	}
+
+	for i, key := range smallest.keys {
+		dst.keys = append(dst.keys, key)
+		dst.containers = append(dst.containers, smallest.containers[i].clone())
+	}
+
+	for _, bm := range bms {
+		if bm == smallest {
+			continue
+		}
+		dst.andInPlace(bm)
+	}
+}
+
+// andInPlace intersects r with other, dropping any container of r whose
+// key is absent from other and And-ing the containers present in both.
+func (r *RoaringBitmapPosting) andInPlace(other *RoaringBitmapPosting) {
+	keys := r.keys[:0]
+	containers := r.containers[:0]
+	for i, key := range r.keys {
+		oi, ok := search(other.keys, key)
+		if !ok {
+			continue
+		}
+		c := r.containers[i].and(other.containers[oi])
+		if c.cardinality() == 0 {
+			continue
+		}
+		keys = append(keys, key)
+		containers = append(containers, c)
+	}
+	r.keys = keys
+	r.containers = containers
+}
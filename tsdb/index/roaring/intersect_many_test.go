@@ -0,0 +1,79 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func buildBitmap(vals ...uint64) *RoaringBitmapPosting {
+	r := NewRoaringBitmapPosting()
+	for _, v := range vals {
+		r.Add(v)
+	}
+	return r
+}
+
+func TestIntersectManyInto(t *testing.T) {
+	a := buildBitmap(1, 2, 3, 4, 5, 1<<16)
+	b := buildBitmap(2, 3, 4, 6, 1<<16)
+	c := buildBitmap(2, 3, 4, 5, 1<<16)
+
+	dst := NewRoaringBitmapPosting()
+	IntersectManyInto(dst, a, b, c)
+
+	testutil.Equals(t, []uint64{2, 3, 4, 1 << 16}, iterateAll(dst))
+}
+
+func TestIntersectManyInto_ReusesDst(t *testing.T) {
+	dst := NewRoaringBitmapPosting()
+	dst.Add(999) // stale data from a previous query
+
+	a := buildBitmap(1, 2, 3)
+	b := buildBitmap(2, 3, 4)
+	IntersectManyInto(dst, a, b)
+
+	testutil.Equals(t, []uint64{2, 3}, iterateAll(dst))
+}
+
+func BenchmarkIntersectManyInto_ReuseVsAllocate(b *testing.B) {
+	var bms []*RoaringBitmapPosting
+	for i := 0; i < 10; i++ {
+		bm := NewRoaringBitmapPosting()
+		for v := uint64(i); v < 1000000; v += 10 {
+			bm.Add(v)
+		}
+		bms = append(bms, bm)
+	}
+
+	b.Run("Reuse", func(bench *testing.B) {
+		dst := NewRoaringBitmapPosting()
+		bench.ResetTimer()
+		bench.ReportAllocs()
+		for i := 0; i < bench.N; i++ {
+			IntersectManyInto(dst, bms...)
+		}
+	})
+
+	b.Run("Allocate", func(bench *testing.B) {
+		bench.ResetTimer()
+		bench.ReportAllocs()
+		for i := 0; i < bench.N; i++ {
+			dst := NewRoaringBitmapPosting()
+			IntersectManyInto(dst, bms...)
+		}
+	})
+}
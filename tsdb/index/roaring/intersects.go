@@ -0,0 +1,40 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// Intersects reports whether r and other share at least one member. It
+// walks containers by matching key the way And does, but returns as soon
+// as a single shared member is found instead of materializing or counting
+// the whole intersection.
+func (r *RoaringBitmapPosting) Intersects(other *RoaringBitmapPosting) bool {
+	ri, oi := 0, 0
+	for ri < len(r.keys) && oi < len(other.keys) {
+		switch {
+		case r.keys[ri] < other.keys[oi]:
+			ri++
+		case r.keys[ri] > other.keys[oi]:
+			oi++
+		default:
+			rc, oc := r.containers[ri], other.containers[oi]
+			for _, v := range rc.toSlice() {
+				if oc.contains(v) {
+					return true
+				}
+			}
+			ri++
+			oi++
+		}
+	}
+	return false
+}
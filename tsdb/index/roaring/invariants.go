@@ -0,0 +1,37 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import "fmt"
+
+// CheckInvariants verifies the internal bookkeeping a RoaringBitmapPosting
+// must maintain: keys and containers are the same length, keys are sorted
+// without duplicates, and every container is non-empty. It is meant for
+// tests that mutate a bitmap directly and want to catch bugs in that
+// mutation rather than downstream, where they would show up as a wrong
+// membership query.
+func (r *RoaringBitmapPosting) CheckInvariants() error {
+	if len(r.keys) != len(r.containers) {
+		return fmt.Errorf("roaring: %d keys but %d containers", len(r.keys), len(r.containers))
+	}
+	for i, key := range r.keys {
+		if i > 0 && key <= r.keys[i-1] {
+			return fmt.Errorf("roaring: keys not strictly increasing at index %d: %d <= %d", i, key, r.keys[i-1])
+		}
+		if r.containers[i].cardinality() == 0 {
+			return fmt.Errorf("roaring: empty container left behind at key %d", key)
+		}
+	}
+	return nil
+}
@@ -1 +1,102 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
 package roaring
+
+// roaringBitmapIterator walks a RoaringBitmapPosting's members in ascending
+// order. It implements the same Next/Seek/At/Err shape as index.Postings so
+// that it can be handed back through that interface without this package
+// depending on it.
+type roaringBitmapIterator struct {
+	bm      *RoaringBitmapPosting
+	ci      int      // index into bm.keys / bm.containers
+	vals    []uint16 // cached member slice of the current container
+	vi      int      // index into vals
+	cur     uint64
+	started bool
+}
+
+// NewRoaringBitmapIterator returns a forward iterator over r's members.
+func NewRoaringBitmapIterator(r *RoaringBitmapPosting) *roaringBitmapIterator {
+	return &roaringBitmapIterator{bm: r, ci: -1}
+}
+
+func (it *roaringBitmapIterator) loadContainer(ci int) {
+	it.ci = ci
+	it.vals = it.bm.containers[ci].toSlice()
+	it.vi = 0
+}
+
+func (it *roaringBitmapIterator) Next() bool {
+	it.started = true
+	for {
+		if it.ci == -1 {
+			if len(it.bm.containers) == 0 {
+				return false
+			}
+			it.loadContainer(0)
+		}
+		if it.vi < len(it.vals) {
+			it.cur = joinBits(it.bm.keys[it.ci], it.vals[it.vi])
+			it.vi++
+			return true
+		}
+		if it.ci+1 >= len(it.bm.containers) {
+			return false
+		}
+		it.loadContainer(it.ci + 1)
+	}
+}
+
+func (it *roaringBitmapIterator) Seek(v uint64) bool {
+	if it.started && it.cur >= v {
+		return true
+	}
+	key := highBits(v)
+	i, _ := search(it.bm.keys, key)
+	if i >= len(it.bm.containers) {
+		it.ci = len(it.bm.containers)
+		return false
+	}
+	it.loadContainer(i)
+	if it.bm.keys[i] == key {
+		low := lowBits(v)
+		for it.vi < len(it.vals) && it.vals[it.vi] < low {
+			it.vi++
+		}
+	}
+	return it.Next()
+}
+
+func (it *roaringBitmapIterator) At() uint64 {
+	return it.cur
+}
+
+func (it *roaringBitmapIterator) Err() error {
+	return nil
+}
+
+// Bitmap returns the underlying bitmap this iterator walks, so that
+// combinators working with index.Postings can recover the bitmap behind an
+// iterator and fall back to a native roaring operation instead of the
+// generic iterator protocol.
+func (it *roaringBitmapIterator) Bitmap() *RoaringBitmapPosting {
+	return it.bm
+}
+
+// Select returns the n-th (0-based) member of the underlying bitmap,
+// ignoring the iterator's current position. It lets callers like
+// index.NthPostings skip straight to a rank without iterating.
+func (it *roaringBitmapIterator) Select(n int) (uint64, bool) {
+	return it.bm.Select(n)
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import "container/heap"
+
+type lazyUnionSource struct {
+	it  *roaringBitmapIterator
+	cur uint64
+}
+
+type lazyUnionHeap []*lazyUnionSource
+
+func (h lazyUnionHeap) Len() int            { return len(h) }
+func (h lazyUnionHeap) Less(i, j int) bool  { return h[i].cur < h[j].cur }
+func (h lazyUnionHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *lazyUnionHeap) Push(x interface{}) { *h = append(*h, x.(*lazyUnionSource)) }
+func (h *lazyUnionHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	last := old[n-1]
+	*h = old[:n-1]
+	return last
+}
+
+// LazyUnion is a forward iterator over the union of several bitmaps,
+// implementing the same Next/Seek/At/Err shape as index.Postings. It keeps
+// one sub-iterator per source bitmap in a min-heap and always advances
+// whichever is smallest, so the union is never materialized into a
+// bitmap of its own: memory use is O(number of sources), not O(union
+// size).
+type LazyUnion struct {
+	sources []*lazyUnionSource
+	h       lazyUnionHeap
+	started bool
+	cur     uint64
+}
+
+// NewLazyUnion returns a LazyUnion over bms. bms may be empty, in which
+// case the returned iterator yields nothing.
+func NewLazyUnion(bms ...*RoaringBitmapPosting) *LazyUnion {
+	sources := make([]*lazyUnionSource, len(bms))
+	for i, bm := range bms {
+		sources[i] = &lazyUnionSource{it: NewRoaringBitmapIterator(bm)}
+	}
+	return &LazyUnion{sources: sources}
+}
+
+func (u *LazyUnion) pushIfAny(s *lazyUnionSource) {
+	if s.it.Next() {
+		s.cur = s.it.At()
+		u.h = append(u.h, s)
+	}
+}
+
+func (u *LazyUnion) init() {
+	u.started = true
+	u.h = make(lazyUnionHeap, 0, len(u.sources))
+	for _, s := range u.sources {
+		u.pushIfAny(s)
+	}
+	heap.Init(&u.h)
+}
+
+func (u *LazyUnion) Next() bool {
+	if !u.started {
+		u.init()
+	}
+	for u.h.Len() > 0 {
+		top := u.h[0]
+		v := top.cur
+		// Drain every source currently sitting on v so the union
+		// doesn't repeat a value shared by more than one bitmap.
+		for u.h.Len() > 0 && u.h[0].cur == v {
+			s := u.h[0]
+			heap.Pop(&u.h)
+			u.pushIfAny(s)
+		}
+		u.cur = v
+		return true
+	}
+	return false
+}
+
+func (u *LazyUnion) Seek(v uint64) bool {
+	if u.started && u.cur >= v {
+		return true
+	}
+	for u.Next() {
+		if u.cur >= v {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *LazyUnion) At() uint64 {
+	return u.cur
+}
+
+func (u *LazyUnion) Err() error {
+	return nil
+}
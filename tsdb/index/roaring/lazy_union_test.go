@@ -0,0 +1,99 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func drainLazyUnion(u *LazyUnion) []uint64 {
+	var got []uint64
+	for u.Next() {
+		got = append(got, u.At())
+	}
+	return got
+}
+
+func TestLazyUnion(t *testing.T) {
+	a := buildBitmap(1, 2, 5, 1000)
+	b := buildBitmap(2, 3, 1000, 1001)
+	c := buildBitmap(4)
+
+	testutil.Equals(t, []uint64{1, 2, 3, 4, 5, 1000, 1001}, drainLazyUnion(NewLazyUnion(a, b, c)))
+}
+
+func TestLazyUnion_Seek(t *testing.T) {
+	a := buildBitmap(1, 5, 9)
+	b := buildBitmap(2, 6, 9)
+
+	u := NewLazyUnion(a, b)
+	testutil.Assert(t, u.Seek(6), "expected Seek(6) to succeed")
+	testutil.Equals(t, uint64(6), u.At())
+	testutil.Assert(t, u.Next(), "expected Next() to succeed")
+	testutil.Equals(t, uint64(9), u.At())
+	testutil.Assert(t, !u.Next(), "expected no more values")
+}
+
+func TestLazyUnion_Empty(t *testing.T) {
+	u := NewLazyUnion()
+	testutil.Assert(t, !u.Next(), "expected empty union to yield nothing")
+}
+
+// materializedUnion is the baseline LazyUnion is benchmarked against: the
+// union actually built into its own bitmap by Adding every member of every
+// input.
+func materializedUnion(bms ...*RoaringBitmapPosting) *RoaringBitmapPosting {
+	dst := NewRoaringBitmapPosting()
+	for _, bm := range bms {
+		it := NewRoaringBitmapIterator(bm)
+		for it.Next() {
+			dst.Add(it.At())
+		}
+	}
+	return dst
+}
+
+func benchmarkBitmaps(n, spread int) []*RoaringBitmapPosting {
+	var bms []*RoaringBitmapPosting
+	for i := 0; i < n; i++ {
+		bm := NewRoaringBitmapPosting()
+		for j := 1; j < spread; j++ {
+			bm.Add(uint64(j))
+		}
+		bms = append(bms, bm)
+	}
+	return bms
+}
+
+func BenchmarkLazyUnion_ManyPostings(b *testing.B) {
+	bms := benchmarkBitmaps(100000, 100)
+
+	b.Run("LazyUnion", func(bench *testing.B) {
+		bench.ReportAllocs()
+		for i := 0; i < bench.N; i++ {
+			u := NewLazyUnion(bms...)
+			for u.Next() {
+			}
+		}
+	})
+
+	b.Run("Materialized", func(bench *testing.B) {
+		bench.ReportAllocs()
+		for i := 0; i < bench.N; i++ {
+			materializedUnion(bms...)
+		}
+	})
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import "bytes"
+
+// MarshalBinary encodes r using the same wire format as WriteTo: a version
+// byte, the container count, and each container's high key plus its
+// big-endian payload, matching the rest of the tsdb index format.
+func (r *RoaringBitmapPosting) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalRoaringBitmapPosting decodes a bitmap previously produced by
+// MarshalBinary or WriteTo.
+func UnmarshalRoaringBitmapPosting(b []byte) (*RoaringBitmapPosting, error) {
+	r := NewRoaringBitmapPosting()
+	if _, err := r.ReadFrom(bytes.NewReader(b)); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,42 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestMarshalBinary_RoundTrip_MixedContainers(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	// Sparse container.
+	r.Add(1)
+	r.Add(5)
+	r.Add(9)
+	// Dense, contiguous container, which Optimize stores as a run container.
+	for i := uint64(0); i < 60000; i++ {
+		r.Add(70000 + i)
+	}
+	r.Optimize()
+
+	want := iterateAll(r)
+
+	b, err := r.MarshalBinary()
+	testutil.Ok(t, err)
+
+	got, err := UnmarshalRoaringBitmapPosting(b)
+	testutil.Ok(t, err)
+	testutil.Equals(t, want, iterateAll(got))
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import "container/heap"
+
+type sortedStream struct {
+	vals []uint64
+}
+
+type streamHeap []sortedStream
+
+func (h streamHeap) Len() int            { return len(h) }
+func (h streamHeap) Less(i, j int) bool  { return h[i].vals[0] < h[j].vals[0] }
+func (h streamHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *streamHeap) Push(x interface{}) { *h = append(*h, x.(sortedStream)) }
+func (h *streamHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	last := old[n-1]
+	*h = old[:n-1]
+	return last
+}
+
+// MergeSortedInto bulk-loads every value from streams into dst with a
+// k-way merge, deduping as it goes. It is meant for rebuilding an index
+// from several sorted ID streams of the same series set, and is more
+// efficient than calling Add once per stream per value since it only
+// visits each container once per distinct value.
+func MergeSortedInto(dst *RoaringBitmapPosting, streams ...[]uint64) {
+	h := make(streamHeap, 0, len(streams))
+	for _, s := range streams {
+		if len(s) > 0 {
+			h = append(h, sortedStream{vals: s})
+		}
+	}
+	heap.Init(&h)
+
+	var last uint64
+	first := true
+	for h.Len() > 0 {
+		s := h[0]
+		v := s.vals[0]
+		if first || v != last {
+			dst.Add(v)
+			last = v
+			first = false
+		}
+		if len(s.vals) > 1 {
+			h[0].vals = s.vals[1:]
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+	}
+}
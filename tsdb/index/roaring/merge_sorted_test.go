@@ -0,0 +1,33 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestMergeSortedInto(t *testing.T) {
+	dst := NewRoaringBitmapPosting()
+
+	MergeSortedInto(dst,
+		[]uint64{1, 2, 5, 1 << 16},
+		[]uint64{2, 3, 5, 6},
+		[]uint64{0, 4, 6, (1 << 16) + 1},
+	)
+
+	testutil.Ok(t, dst.CheckInvariants())
+	testutil.Equals(t, []uint64{0, 1, 2, 3, 4, 5, 6, 1 << 16, (1 << 16) + 1}, iterateAll(dst))
+}
@@ -0,0 +1,35 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// Min returns the smallest member of r. ok is false if r is empty.
+//
+// Because containers are kept sorted by key, the smallest member always
+// lives in the first container, so this is O(1) in the number of
+// containers regardless of which representation that container uses.
+func (r *RoaringBitmapPosting) Min() (uint64, bool) {
+	if len(r.containers) == 0 {
+		return 0, false
+	}
+	return joinBits(r.keys[0], r.containers[0].minimum()), true
+}
+
+// Max returns the largest member of r. ok is false if r is empty.
+func (r *RoaringBitmapPosting) Max() (uint64, bool) {
+	if len(r.containers) == 0 {
+		return 0, false
+	}
+	last := len(r.containers) - 1
+	return joinBits(r.keys[last], r.containers[last].maximum()), true
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestMinMax_Empty(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	_, ok := r.Min()
+	testutil.Assert(t, !ok, "expected Min to report not-ok for an empty bitmap")
+	_, ok = r.Max()
+	testutil.Assert(t, !ok, "expected Max to report not-ok for an empty bitmap")
+}
+
+func TestMinMax_Simple(t *testing.T) {
+	r := buildBitmap(5, 1, 70003, 70000)
+	min, ok := r.Min()
+	testutil.Assert(t, ok, "expected Min to report ok")
+	testutil.Equals(t, uint64(1), min)
+
+	max, ok := r.Max()
+	testutil.Assert(t, ok, "expected Max to report ok")
+	testutil.Equals(t, uint64(70003), max)
+}
+
+// TestMinMax_ArrayFirstBitmapLast covers the case where the first container
+// (holding the minimum) stays an array, while the last container (holding
+// the maximum) is dense enough that Optimize promotes it to a bitmap
+// container.
+func TestMinMax_ArrayFirstBitmapLast(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	r.Add(1)
+	r.Add(2)
+	for i := uint64(0); i < 60000; i++ {
+		r.Add(70000 + i*2)
+	}
+	r.Optimize()
+
+	stats := r.ContainerStats()
+	testutil.Equals(t, ContainerArray, stats[0].Type)
+	testutil.Equals(t, ContainerBitmap, stats[len(stats)-1].Type)
+
+	min, ok := r.Min()
+	testutil.Assert(t, ok, "expected Min to report ok")
+	testutil.Equals(t, uint64(1), min)
+
+	max, ok := r.Max()
+	testutil.Assert(t, ok, "expected Max to report ok")
+	testutil.Equals(t, uint64(70000+59999*2), max)
+}
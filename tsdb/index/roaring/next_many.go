@@ -0,0 +1,49 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// NextMany fills buf with up to len(buf) successive elements and returns
+// how many were written, advancing the iterator as if that many Next/At
+// pairs had been called. It returns 0 once the iterator is exhausted.
+//
+// Unlike calling Next/At in a loop, it copies runs of already-materialized
+// container values directly into buf, only paying per-element Next/At
+// overhead at container boundaries. This amortizes the per-element call
+// overhead for hot query paths that consume a whole bitmap in bulk.
+func (it *roaringBitmapIterator) NextMany(buf []uint64) int {
+	it.started = true
+	n := 0
+	for n < len(buf) {
+		if it.ci == -1 {
+			if len(it.bm.containers) == 0 {
+				return n
+			}
+			it.loadContainer(0)
+		}
+		for n < len(buf) && it.vi < len(it.vals) {
+			it.cur = joinBits(it.bm.keys[it.ci], it.vals[it.vi])
+			buf[n] = it.cur
+			it.vi++
+			n++
+		}
+		if it.vi >= len(it.vals) {
+			if it.ci+1 >= len(it.bm.containers) {
+				it.ci = len(it.bm.containers)
+				return n
+			}
+			it.loadContainer(it.ci + 1)
+		}
+	}
+	return n
+}
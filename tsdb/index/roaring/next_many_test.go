@@ -0,0 +1,92 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestNextMany_MatchesScalarLoop(t *testing.T) {
+	r := buildBitmap(1, 2, 3, 70000, 70001, 140000, 140001, 140002)
+
+	it := NewRoaringBitmapIterator(r)
+	var got []uint64
+	buf := make([]uint64, 3)
+	for {
+		n := it.NextMany(buf)
+		if n == 0 {
+			break
+		}
+		got = append(got, buf[:n]...)
+	}
+	testutil.Equals(t, iterateAll(r), got)
+	testutil.Equals(t, 0, it.NextMany(buf))
+}
+
+func TestNextMany_InterleavedWithNext(t *testing.T) {
+	r := buildBitmap(1, 2, 3, 4, 5)
+
+	it := NewRoaringBitmapIterator(r)
+	testutil.Assert(t, it.Next(), "expected first Next to succeed")
+	testutil.Equals(t, uint64(1), it.At())
+
+	buf := make([]uint64, 2)
+	n := it.NextMany(buf)
+	testutil.Equals(t, 2, n)
+	testutil.Equals(t, []uint64{2, 3}, buf)
+
+	testutil.Assert(t, it.Next(), "expected Next after NextMany to continue in order")
+	testutil.Equals(t, uint64(4), it.At())
+}
+
+func benchmarkBitmap10MForIteration() *RoaringBitmapPosting {
+	r := NewRoaringBitmapPosting()
+	for v := uint64(0); v < 10_000_000; v++ {
+		r.Add(v)
+	}
+	return r
+}
+
+func BenchmarkIterate_ScalarLoop(b *testing.B) {
+	r := benchmarkBitmap10MForIteration()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		it := NewRoaringBitmapIterator(r)
+		var sum uint64
+		for it.Next() {
+			sum += it.At()
+		}
+	}
+}
+
+func BenchmarkIterate_NextMany(b *testing.B) {
+	r := benchmarkBitmap10MForIteration()
+	buf := make([]uint64, 4096)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		it := NewRoaringBitmapIterator(r)
+		var sum uint64
+		for {
+			n := it.NextMany(buf)
+			if n == 0 {
+				break
+			}
+			for _, v := range buf[:n] {
+				sum += v
+			}
+		}
+	}
+}
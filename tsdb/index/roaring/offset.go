@@ -0,0 +1,37 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import "math"
+
+// OffsetBy returns a new bitmap with every ID of r increased by delta,
+// rebuilding containers under their new high bits. If adding delta to the
+// largest member would overflow math.MaxUint64, OffsetBy clamps that member
+// (and any other that would overflow) to math.MaxUint64 rather than
+// wrapping around.
+func (r *RoaringBitmapPosting) OffsetBy(delta uint64) *RoaringBitmapPosting {
+	out := NewRoaringBitmapPosting()
+	for i, c := range r.containers {
+		key := r.keys[i]
+		for _, low := range c.toSlice() {
+			v := joinBits(key, low)
+			if delta > math.MaxUint64-v {
+				out.Add(math.MaxUint64)
+				continue
+			}
+			out.Add(v + delta)
+		}
+	}
+	return out
+}
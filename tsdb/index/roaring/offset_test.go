@@ -0,0 +1,41 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_OffsetBy(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	for _, v := range []uint64{1, 2, (1 << 16) - 1, 1 << 16} {
+		r.Add(v)
+	}
+
+	got := r.OffsetBy(10)
+
+	testutil.Equals(t, []uint64{11, 12, (1 << 16) + 9, (1 << 16) + 10}, iterateAll(got))
+}
+
+func TestRoaringBitmapPosting_OffsetBy_Overflow(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	r.Add(math.MaxUint64 - 1)
+
+	got := r.OffsetBy(5)
+
+	testutil.Equals(t, []uint64{math.MaxUint64}, iterateAll(got))
+}
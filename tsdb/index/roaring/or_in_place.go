@@ -0,0 +1,49 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// OrInPlace mutates r to hold the union of r and o, without allocating a
+// fresh result bitmap the way Or does: containers that exist only on one
+// side are reused directly (cloned from o, kept as-is from r), and only
+// keys present on both sides get combined via their container's own or
+// method. o is left unmodified.
+func (r *RoaringBitmapPosting) OrInPlace(o *RoaringBitmapPosting) {
+	r.checkMutable()
+	r.cardCacheValid = false
+	r.treeCacheValid = false
+
+	keys := make([]uint64, 0, len(r.keys)+len(o.keys))
+	containers := make([]container, 0, len(r.keys)+len(o.keys))
+
+	ri, oi := 0, 0
+	for ri < len(r.keys) || oi < len(o.keys) {
+		switch {
+		case oi >= len(o.keys) || (ri < len(r.keys) && r.keys[ri] < o.keys[oi]):
+			keys = append(keys, r.keys[ri])
+			containers = append(containers, r.containers[ri])
+			ri++
+		case ri >= len(r.keys) || o.keys[oi] < r.keys[ri]:
+			keys = append(keys, o.keys[oi])
+			containers = append(containers, o.containers[oi].clone())
+			oi++
+		default:
+			keys = append(keys, r.keys[ri])
+			containers = append(containers, r.containers[ri].or(o.containers[oi]))
+			ri++
+			oi++
+		}
+	}
+	r.keys = keys
+	r.containers = containers
+}
@@ -0,0 +1,47 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_OrInPlace(t *testing.T) {
+	a := buildBitmap(1, 2, 70000)
+	b := buildBitmap(2, 3, 140000)
+
+	a.OrInPlace(b)
+	testutil.Equals(t, []uint64{1, 2, 3, 70000, 140000}, iterateAll(a))
+
+	// b must be untouched.
+	testutil.Equals(t, []uint64{2, 3, 140000}, iterateAll(b))
+}
+
+func TestRoaringBitmapPosting_OrInPlace_DisjointSingles(t *testing.T) {
+	dst := NewRoaringBitmapPosting()
+	for i := uint64(0); i < 1000; i++ {
+		single := NewRoaringBitmapPosting()
+		single.Add(i * 2)
+		dst.OrInPlace(single)
+	}
+
+	testutil.Equals(t, uint64(1000), dst.Cardinality())
+	want := make([]uint64, 1000)
+	for i := range want {
+		want[i] = uint64(i * 2)
+	}
+	testutil.Equals(t, want, iterateAll(dst))
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import "sort"
+
+type keyedContainer struct {
+	key uint64
+	c   container
+}
+
+// OrMany returns the union of all bms. It groups every input container by
+// key up front and ORs each group once, rather than folding bitmaps
+// together pairwise with Or/OrInPlace, which would cost O(n^2) work when
+// merging many bitmaps since each fold re-touches the whole result so far.
+func OrMany(bms ...*RoaringBitmapPosting) *RoaringBitmapPosting {
+	dst := NewRoaringBitmapPosting()
+	if len(bms) == 0 {
+		return dst
+	}
+
+	total := 0
+	for _, bm := range bms {
+		total += len(bm.keys)
+	}
+	all := make([]keyedContainer, 0, total)
+	for _, bm := range bms {
+		for i, key := range bm.keys {
+			all = append(all, keyedContainer{key: key, c: bm.containers[i]})
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].key < all[j].key })
+
+	for i := 0; i < len(all); {
+		j := i + 1
+		for j < len(all) && all[j].key == all[i].key {
+			j++
+		}
+
+		var merged container
+		if j-i == 1 {
+			merged = all[i].c.clone()
+		} else {
+			// Folding containers together pairwise via or() would cost
+			// O(group size^2) once many containers share a key (e.g.
+			// many single-element bitmaps landing in the same 65536-wide
+			// span). Instead, collect every member once and build the
+			// merged container's best representation directly.
+			vals := make([]uint16, 0, j-i)
+			for k := i; k < j; k++ {
+				vals = append(vals, all[k].c.toSlice()...)
+			}
+			sort.Slice(vals, func(a, b int) bool { return vals[a] < vals[b] })
+			arr := newArrayContainer()
+			for _, v := range vals {
+				if len(arr.vals) == 0 || arr.vals[len(arr.vals)-1] != v {
+					arr.vals = append(arr.vals, v)
+				}
+			}
+			merged = chooseContainer(arr)
+		}
+
+		if merged.cardinality() > 0 {
+			dst.keys = append(dst.keys, all[i].key)
+			dst.containers = append(dst.containers, merged)
+		}
+		i = j
+	}
+	return dst
+}
@@ -0,0 +1,51 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestOrMany(t *testing.T) {
+	a := buildBitmap(1, 2, 70000)
+	b := buildBitmap(2, 3, 140000)
+	c := buildBitmap(70000, 70001)
+
+	got := OrMany(a, b, c)
+	testutil.Equals(t, []uint64{1, 2, 3, 70000, 70001, 140000}, iterateAll(got))
+
+	// Inputs must be left untouched.
+	testutil.Equals(t, []uint64{1, 2, 70000}, iterateAll(a))
+}
+
+func TestOrMany_Empty(t *testing.T) {
+	testutil.Assert(t, OrMany().isEmpty(), "expected OrMany of no bitmaps to be empty")
+}
+
+func BenchmarkOrMany_100000SmallBitmaps(b *testing.B) {
+	bms := make([]*RoaringBitmapPosting, 100000)
+	for i := range bms {
+		bm := NewRoaringBitmapPosting()
+		bm.Add(uint64(i))
+		bms[i] = bm
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		OrMany(bms...)
+	}
+}
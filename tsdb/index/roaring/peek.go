@@ -0,0 +1,29 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// Peek returns the value the next Next call would produce, without
+// consuming it: the iterator's position is restored before returning, so
+// repeated Peek calls and a subsequent Next/Seek all behave as if Peek had
+// never been called.
+func (it *roaringBitmapIterator) Peek() (uint64, bool) {
+	ci, vals, vi, cur, started := it.ci, it.vals, it.vi, it.cur, it.started
+	ok := it.Next()
+	var v uint64
+	if ok {
+		v = it.cur
+	}
+	it.ci, it.vals, it.vi, it.cur, it.started = ci, vals, vi, cur, started
+	return v, ok
+}
@@ -0,0 +1,64 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestPeek_RepeatedCallsAreConsistent(t *testing.T) {
+	r := buildBitmap(1, 2, 3)
+	it := NewRoaringBitmapIterator(r)
+
+	v, ok := it.Peek()
+	testutil.Assert(t, ok, "expected Peek on a fresh iterator to find a value")
+	testutil.Equals(t, uint64(1), v)
+
+	v, ok = it.Peek()
+	testutil.Assert(t, ok, "expected a second Peek to report the same value")
+	testutil.Equals(t, uint64(1), v)
+
+	testutil.Assert(t, it.Next(), "expected Next after Peek to consume the peeked value")
+	testutil.Equals(t, uint64(1), it.At())
+
+	v, ok = it.Peek()
+	testutil.Assert(t, ok, "expected Peek to advance past the consumed value")
+	testutil.Equals(t, uint64(2), v)
+}
+
+func TestPeek_InterleavedWithSeek(t *testing.T) {
+	r := buildBitmap(1, 2, 70000, 70001)
+	it := NewRoaringBitmapIterator(r)
+
+	testutil.Assert(t, it.Seek(70000), "expected Seek to land on 70000")
+	testutil.Equals(t, uint64(70000), it.At())
+
+	v, ok := it.Peek()
+	testutil.Assert(t, ok, "expected Peek after Seek to find the next value")
+	testutil.Equals(t, uint64(70001), v)
+
+	// Peek must not have disturbed the iterator's position for Seek.
+	testutil.Assert(t, !it.Seek(1<<20), "expected Seek past the end to fail")
+}
+
+func TestPeek_ExhaustedIterator(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	it := NewRoaringBitmapIterator(r)
+
+	_, ok := it.Peek()
+	testutil.Assert(t, !ok, "expected Peek on an empty bitmap to report not-ok")
+	testutil.Assert(t, !it.Next(), "expected Next after Peek on an empty bitmap to still fail")
+}
@@ -0,0 +1,29 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// PopcountContainers returns the total cardinality held by each container
+// representation, so operators can chart array-held vs bitmap-held vs
+// run-held cardinality as a Prometheus metric on index shape.
+func (r *RoaringBitmapPosting) PopcountContainers() map[ContainerType]uint64 {
+	counts := map[ContainerType]uint64{
+		ContainerArray:  0,
+		ContainerBitmap: 0,
+		ContainerRun:    0,
+	}
+	for _, c := range r.containers {
+		counts[c.typ()] += uint64(c.cardinality())
+	}
+	return counts
+}
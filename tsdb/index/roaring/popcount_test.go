@@ -0,0 +1,41 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_PopcountContainers(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+
+	// Container 0: sparse and non-contiguous, stays an array of 3.
+	r.Add(1)
+	r.Add(1000)
+	r.Add(60000)
+
+	// Container 1: dense but non-contiguous, so Compact picks a bitmap.
+	const denseStart = uint64(1) << 16
+	for i := uint64(0); i < 40000; i += 2 {
+		r.Add(denseStart + i)
+	}
+	r.Compact()
+
+	counts := r.PopcountContainers()
+	testutil.Equals(t, uint64(3), counts[ContainerArray])
+	testutil.Equals(t, uint64(20000), counts[ContainerBitmap])
+	testutil.Equals(t, uint64(0), counts[ContainerRun])
+}
@@ -0,0 +1,78 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// roaringBitmapRangeIterator walks a RoaringBitmapPosting's members that
+// fall in [lo, hi), starting directly at the container covering lo instead
+// of scanning from the beginning of the bitmap, and stopping as soon as hi
+// is reached.
+type roaringBitmapRangeIterator struct {
+	inner   *roaringBitmapIterator
+	hi      uint64
+	seeked  bool // whether inner already sits on the first in-range value
+	started bool
+	done    bool
+	cur     uint64
+}
+
+// NewRoaringBitmapRangeIterator returns a forward iterator over r's members
+// in [lo, hi).
+func NewRoaringBitmapRangeIterator(r *RoaringBitmapPosting, lo, hi uint64) *roaringBitmapRangeIterator {
+	it := NewRoaringBitmapIterator(r)
+	if hi <= lo {
+		return &roaringBitmapRangeIterator{inner: it, hi: hi, done: true}
+	}
+	return &roaringBitmapRangeIterator{inner: it, hi: hi, seeked: it.Seek(lo)}
+}
+
+func (it *roaringBitmapRangeIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	it.started = true
+	if it.seeked {
+		it.seeked = false
+	} else if !it.inner.Next() {
+		it.done = true
+		return false
+	}
+	if v := it.inner.At(); v < it.hi {
+		it.cur = v
+		return true
+	}
+	it.done = true
+	return false
+}
+
+func (it *roaringBitmapRangeIterator) Seek(v uint64) bool {
+	if it.started && it.cur >= v {
+		return true
+	}
+	it.started = true
+	it.seeked = false
+	if v < it.hi && it.inner.Seek(v) && it.inner.At() < it.hi {
+		it.cur = it.inner.At()
+		return true
+	}
+	it.done = true
+	return false
+}
+
+func (it *roaringBitmapRangeIterator) At() uint64 {
+	return it.cur
+}
+
+func (it *roaringBitmapRangeIterator) Err() error {
+	return nil
+}
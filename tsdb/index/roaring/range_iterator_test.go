@@ -0,0 +1,66 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func drainRange(it *roaringBitmapRangeIterator) []uint64 {
+	var got []uint64
+	for it.Next() {
+		got = append(got, it.At())
+	}
+	return got
+}
+
+func filterFullIterator(r *RoaringBitmapPosting, lo, hi uint64) []uint64 {
+	var got []uint64
+	it := NewRoaringBitmapIterator(r)
+	for it.Next() {
+		if v := it.At(); v >= lo && v < hi {
+			got = append(got, v)
+		}
+	}
+	return got
+}
+
+func TestRoaringBitmapRangeIterator(t *testing.T) {
+	r := buildBitmap(1, 5, 70000, 70001, 140000)
+
+	for _, c := range []struct{ lo, hi uint64 }{
+		{0, 1 << 63},
+		{5, 70001},
+		{70001, 70001},
+		{2, 6},
+		{70000, 200000},
+	} {
+		want := filterFullIterator(r, c.lo, c.hi)
+		got := drainRange(NewRoaringBitmapRangeIterator(r, c.lo, c.hi))
+		testutil.Equals(t, want, got)
+	}
+}
+
+func TestRoaringBitmapRangeIterator_Seek(t *testing.T) {
+	r := buildBitmap(1, 5, 70000, 70001, 140000)
+
+	it := NewRoaringBitmapRangeIterator(r, 5, 140000)
+	testutil.Assert(t, it.Seek(70000), "expected Seek(70000) to succeed")
+	testutil.Equals(t, uint64(70000), it.At())
+	testutil.Assert(t, it.Next(), "expected Next() to succeed")
+	testutil.Equals(t, uint64(70001), it.At())
+	testutil.Assert(t, !it.Next(), "expected no more in-range values")
+}
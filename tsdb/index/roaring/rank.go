@@ -0,0 +1,33 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// Rank returns the number of members of r that are <= x. Containers keyed
+// below x contribute their full cardinality; the container holding x (if
+// any) contributes an in-container rank via rankLE.
+func (r *RoaringBitmapPosting) Rank(x uint64) uint64 {
+	key := highBits(x)
+	var n uint64
+	for i, k := range r.keys {
+		switch {
+		case k < key:
+			n += uint64(r.containers[i].cardinality())
+		case k == key:
+			n += uint64(r.containers[i].rankLE(lowBits(x)))
+		default:
+			return n
+		}
+	}
+	return n
+}
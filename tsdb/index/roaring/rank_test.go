@@ -0,0 +1,33 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRank_ContainerBoundariesAndBeyondMax(t *testing.T) {
+	r := buildBitmap(1, 2, 70000, 70001, 140000)
+
+	testutil.Equals(t, uint64(0), r.Rank(0))
+	testutil.Equals(t, uint64(1), r.Rank(1))
+	testutil.Equals(t, uint64(2), r.Rank(2))
+	testutil.Equals(t, uint64(2), r.Rank(69999))
+	testutil.Equals(t, uint64(3), r.Rank(70000))
+	testutil.Equals(t, uint64(4), r.Rank(70001))
+	testutil.Equals(t, uint64(5), r.Rank(140000))
+	testutil.Equals(t, uint64(5), r.Rank(1<<20))
+}
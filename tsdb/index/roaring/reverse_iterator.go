@@ -0,0 +1,98 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// roaringBitmapReverseIterator walks a RoaringBitmapPosting's members in
+// descending order. It mirrors roaringBitmapIterator's Next/Seek/At/Err
+// shape, except Next moves towards smaller values and Seek moves to v or
+// the largest value below it.
+type roaringBitmapReverseIterator struct {
+	bm      *RoaringBitmapPosting
+	ci      int // index into bm.keys / bm.containers, -1 once exhausted
+	vals    []uint16
+	vi      int // index into vals, -1 once the current container is exhausted
+	cur     uint64
+	started bool
+}
+
+// NewRoaringBitmapReverseIterator returns a reverse iterator over r's
+// members, starting above the largest one.
+func NewRoaringBitmapReverseIterator(r *RoaringBitmapPosting) *roaringBitmapReverseIterator {
+	return &roaringBitmapReverseIterator{bm: r, ci: len(r.containers)}
+}
+
+func (it *roaringBitmapReverseIterator) loadContainer(ci int) {
+	it.ci = ci
+	it.vals = it.bm.containers[ci].toSlice()
+	it.vi = len(it.vals) - 1
+}
+
+func (it *roaringBitmapReverseIterator) Next() bool {
+	it.started = true
+	for {
+		if it.ci < 0 {
+			return false
+		}
+		if it.ci == len(it.bm.containers) {
+			if len(it.bm.containers) == 0 {
+				it.ci = -1
+				return false
+			}
+			it.loadContainer(len(it.bm.containers) - 1)
+		}
+		if it.vi >= 0 {
+			it.cur = joinBits(it.bm.keys[it.ci], it.vals[it.vi])
+			it.vi--
+			return true
+		}
+		if it.ci == 0 {
+			it.ci = -1
+			return false
+		}
+		it.loadContainer(it.ci - 1)
+	}
+}
+
+// Seek advances the iterator to v or the largest value below it, and
+// returns true if such a value exists.
+func (it *roaringBitmapReverseIterator) Seek(v uint64) bool {
+	if it.started && it.cur <= v {
+		return true
+	}
+	key := highBits(v)
+	i, exact := search(it.bm.keys, key)
+	if i == 0 && !exact {
+		it.ci = -1
+		return false
+	}
+	if !exact {
+		i--
+	}
+	it.loadContainer(i)
+	if it.bm.keys[i] == key {
+		low := lowBits(v)
+		for it.vi >= 0 && it.vals[it.vi] > low {
+			it.vi--
+		}
+	}
+	return it.Next()
+}
+
+func (it *roaringBitmapReverseIterator) At() uint64 {
+	return it.cur
+}
+
+func (it *roaringBitmapReverseIterator) Err() error {
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapReverseIterator_MultipleContainers(t *testing.T) {
+	r := buildBitmap(1, 2, 70000, 70001, 140000)
+
+	it := NewRoaringBitmapReverseIterator(r)
+	var got []uint64
+	for it.Next() {
+		got = append(got, it.At())
+	}
+	testutil.Equals(t, []uint64{140000, 70001, 70000, 2, 1}, got)
+	testutil.Assert(t, !it.Next(), "expected reverse iterator to stay exhausted past the smallest element")
+}
+
+func TestRoaringBitmapReverseIterator_Seek(t *testing.T) {
+	r := buildBitmap(1, 2, 70000, 70001, 140000)
+
+	it := NewRoaringBitmapReverseIterator(r)
+	testutil.Assert(t, it.Seek(70000), "expected Seek to find a value at or below 70000")
+	testutil.Equals(t, uint64(70000), it.At())
+
+	var got []uint64
+	got = append(got, it.At())
+	for it.Next() {
+		got = append(got, it.At())
+	}
+	testutil.Equals(t, []uint64{70000, 2, 1}, got)
+
+	it2 := NewRoaringBitmapReverseIterator(r)
+	testutil.Assert(t, !it2.Seek(0), "expected Seek(0) to fail since nothing is <= 0")
+}
@@ -11,8 +11,338 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// Package roaring implements a roaring-bitmap-style postings representation,
+// splitting a uint64 ID space into fixed 65536-wide containers that can each
+// independently choose an array, bitmap, or run-length representation.
 package roaring
 
-type Bitmap struct {
+// RoaringBitmapPosting is a sorted set of uint64 IDs, stored as a sequence of
+// containers keyed by the high bits of the ID. It is not safe for concurrent
+// use unless stated otherwise on individual methods.
+type RoaringBitmapPosting struct {
+	keys       []uint64
+	containers []container
+	frozen     bool
 
-}
\ No newline at end of file
+	// cardCache backs CardinalityCached. It is invalidated by every
+	// mutating method (Add, Remove, AddRange, RemoveRange, Clear).
+	cardCacheValid bool
+	cardCache      uint64
+
+	// treeCache backs Contains' container lookup. It mirrors cardCache:
+	// invalidated by the same mutating methods, and rebuilt from
+	// keys/containers on next use rather than kept incrementally in sync,
+	// since most mutations touch keys/containers directly rather than
+	// going through the tree (see containerTree's doc comment).
+	treeCacheValid bool
+	treeCache      *tree
+}
+
+// NewRoaringBitmapPosting returns an empty bitmap.
+func NewRoaringBitmapPosting() *RoaringBitmapPosting {
+	return &RoaringBitmapPosting{}
+}
+
+// NewRoaringRange returns a bitmap containing every ID in [start, end),
+// built directly from full-container run fills rather than one Add per ID.
+// It is meant for test fixtures and block building where series get
+// contiguous ID ranges.
+func NewRoaringRange(start, end uint64) *RoaringBitmapPosting {
+	r := NewRoaringBitmapPosting()
+	if end <= start {
+		return r
+	}
+	last := end - 1
+	startKey, endKey := highBits(start), highBits(last)
+
+	for key := startKey; key <= endKey; key++ {
+		lo, hi := uint16(0), uint16(0xffff)
+		if key == startKey {
+			lo = lowBits(start)
+		}
+		if key == endKey {
+			hi = lowBits(last)
+		}
+		rc := newRunContainer()
+		rc.runs = []run{{start: lo, length: hi - lo}}
+		r.keys = append(r.keys, key)
+		r.containers = append(r.containers, rc)
+		if key == endKey {
+			break
+		}
+	}
+	return r
+}
+
+// containerFor returns the index of the container for key, creating a fresh
+// array container at the right position if none exists yet.
+func (r *RoaringBitmapPosting) containerFor(key uint64) int {
+	i, ok := search(r.keys, key)
+	if ok {
+		return i
+	}
+	r.keys = append(r.keys, 0)
+	copy(r.keys[i+1:], r.keys[i:])
+	r.keys[i] = key
+
+	r.containers = append(r.containers, nil)
+	copy(r.containers[i+1:], r.containers[i:])
+	r.containers[i] = newArrayContainer()
+	return i
+}
+
+// Add inserts v into the bitmap. It is a no-op if v is already a member.
+//
+// containerFor still locates the target container by binary search rather
+// than through treeCache: it needs the container's index in keys/containers
+// to splice in a new entry when key has no container yet, which the tree
+// doesn't track, so resolving the same lookup a second time through the
+// tree first would only add work. The cache is invalidated below so the
+// next Contains rebuilds it from the now-current containers.
+func (r *RoaringBitmapPosting) Add(v uint64) {
+	r.checkMutable()
+	r.cardCacheValid = false
+	r.treeCacheValid = false
+	i := r.containerFor(highBits(v))
+	r.containers[i] = growIfDense(r.containers[i].add(lowBits(v)))
+}
+
+// Remove deletes v from the bitmap. It is a no-op if v is not a member. An
+// empty container is dropped so that isEmpty and Cardinality stay accurate.
+func (r *RoaringBitmapPosting) Remove(v uint64) {
+	r.checkMutable()
+	r.cardCacheValid = false
+	r.treeCacheValid = false
+	key := highBits(v)
+	i, ok := search(r.keys, key)
+	if !ok {
+		return
+	}
+	r.containers[i] = shrinkIfSparse(r.containers[i].remove(lowBits(v)))
+	if r.containers[i].cardinality() == 0 {
+		r.keys = append(r.keys[:i], r.keys[i+1:]...)
+		r.containers = append(r.containers[:i], r.containers[i+1:]...)
+	}
+}
+
+// Contains reports whether v is a member of the bitmap, resolving its
+// container through treeCache (rebuilt on demand by containerTreeCached)
+// instead of a direct binary search over keys.
+func (r *RoaringBitmapPosting) Contains(v uint64) bool {
+	c, ok := containerViaTree(r.containerTreeCached(), highBits(v))
+	if !ok {
+		return false
+	}
+	return c.contains(lowBits(v))
+}
+
+// Cardinality returns the number of members in the bitmap.
+func (r *RoaringBitmapPosting) Cardinality() uint64 {
+	var n uint64
+	for _, c := range r.containers {
+		n += uint64(c.cardinality())
+	}
+	return n
+}
+
+// CountRange returns the number of members in [lo, hi). It only touches
+// containers whose key range overlaps the window, so it is cheap even for a
+// huge bitmap when the window is narrow.
+func (r *RoaringBitmapPosting) CountRange(lo, hi uint64) uint64 {
+	if hi <= lo {
+		return 0
+	}
+	loKey, hiKey := highBits(lo), highBits(hi-1)
+
+	start, _ := search(r.keys, loKey)
+	var n uint64
+	for i := start; i < len(r.keys) && r.keys[i] <= hiKey; i++ {
+		c := r.containers[i]
+		switch {
+		case r.keys[i] > loKey && r.keys[i] < hiKey:
+			// Fully covered by the window.
+			n += uint64(c.cardinality())
+		default:
+			for _, v := range c.toSlice() {
+				id := joinBits(r.keys[i], v)
+				if id >= lo && id < hi {
+					n++
+				}
+			}
+		}
+	}
+	return n
+}
+
+// AndCardinalityRange returns the number of IDs shared by r and other that
+// fall within [lo, hi), without materializing the intersection. It only
+// touches containers from both bitmaps that overlap the window.
+func (r *RoaringBitmapPosting) AndCardinalityRange(other *RoaringBitmapPosting, lo, hi uint64) uint64 {
+	if hi <= lo {
+		return 0
+	}
+	loKey, hiKey := highBits(lo), highBits(hi-1)
+
+	ri, _ := search(r.keys, loKey)
+	oi, _ := search(other.keys, loKey)
+
+	var n uint64
+	for ri < len(r.keys) && r.keys[ri] <= hiKey && oi < len(other.keys) && other.keys[oi] <= hiKey {
+		switch {
+		case r.keys[ri] < other.keys[oi]:
+			ri++
+		case r.keys[ri] > other.keys[oi]:
+			oi++
+		default:
+			key := r.keys[ri]
+			for _, v := range r.containers[ri].toSlice() {
+				if !other.containers[oi].contains(v) {
+					continue
+				}
+				id := joinBits(key, v)
+				if id >= lo && id < hi {
+					n++
+				}
+			}
+			ri++
+			oi++
+		}
+	}
+	return n
+}
+
+// Select returns the n-th (0-based) member of the bitmap in ascending
+// order, walking containers in key order and using each container's own
+// selectAt so dense containers don't need a full scan.
+func (r *RoaringBitmapPosting) Select(n int) (uint64, bool) {
+	if n < 0 {
+		return 0, false
+	}
+	for i, c := range r.containers {
+		card := c.cardinality()
+		if n < card {
+			low, ok := c.selectAt(n)
+			if !ok {
+				return 0, false
+			}
+			return joinBits(r.keys[i], low), true
+		}
+		n -= card
+	}
+	return 0, false
+}
+
+// isEmpty reports whether the bitmap has no members.
+func (r *RoaringBitmapPosting) isEmpty() bool {
+	return len(r.containers) == 0
+}
+
+// chooseContainer converts c to whichever of array/bitmap/run uses the least
+// memory for its current membership, without changing membership.
+func chooseContainer(c container) container {
+	vals := c.toSlice()
+	if len(vals) == 0 {
+		return c
+	}
+
+	arr := newArrayContainer()
+	arr.vals = append(arr.vals, vals...)
+
+	bmp := newBitmapContainer()
+	for _, v := range vals {
+		bmp.add(v)
+	}
+
+	run := newRunContainer()
+	for _, v := range vals {
+		run.add(v)
+	}
+
+	best := container(arr)
+	for _, cand := range []container{bmp, run} {
+		if cand.sizeBytes() < best.sizeBytes() {
+			best = cand
+		}
+	}
+	return best
+}
+
+// Compact re-selects the smallest-memory container representation (array,
+// bitmap, or run) for every container in the bitmap, without changing
+// membership.
+func (r *RoaringBitmapPosting) Compact() {
+	r.checkMutable()
+	for i, c := range r.containers {
+		r.containers[i] = chooseContainer(c)
+	}
+}
+
+// RunOptimize converts any container that is mostly contiguous runs into a
+// run container, which is cheaper to store and scan than an array or bitmap
+// covering the same ranges.
+func (r *RoaringBitmapPosting) RunOptimize() {
+	r.checkMutable()
+	for i, c := range r.containers {
+		if c.typ() == ContainerRun {
+			continue
+		}
+		run := newRunContainer()
+		for _, v := range c.toSlice() {
+			run.add(v)
+		}
+		if run.sizeBytes() < c.sizeBytes() {
+			r.containers[i] = run
+		}
+	}
+}
+
+// ShrinkToFit drops any empty containers left behind by mutation and
+// re-selects the best representation for the rest via Compact.
+func (r *RoaringBitmapPosting) ShrinkToFit() {
+	r.checkMutable()
+	keys := r.keys[:0]
+	containers := r.containers[:0]
+	for i, c := range r.containers {
+		if c.cardinality() == 0 {
+			continue
+		}
+		keys = append(keys, r.keys[i])
+		containers = append(containers, c)
+	}
+	r.keys = keys
+	r.containers = containers
+	r.Compact()
+}
+
+// Optimize ties ShrinkToFit, RunOptimize, and Compact together in a single
+// pass so every container ends up in its minimal-memory representation:
+// array if sparse, run if long runs, bitmap otherwise.
+func (r *RoaringBitmapPosting) Optimize() {
+	r.ShrinkToFit()
+	r.RunOptimize()
+	r.Compact()
+}
+
+// ContainerStat describes the physical layout of a single container.
+type ContainerStat struct {
+	HighKey     uint64
+	Type        ContainerType
+	Cardinality int
+	SizeBytes   uint64
+}
+
+// ContainerStats reports the per-container layout of the bitmap, in
+// ascending key order. It is read-only introspection meant for debugging
+// index bloat.
+func (r *RoaringBitmapPosting) ContainerStats() []ContainerStat {
+	stats := make([]ContainerStat, len(r.containers))
+	for i, c := range r.containers {
+		stats[i] = ContainerStat{
+			HighKey:     r.keys[i],
+			Type:        c.typ(),
+			Cardinality: c.cardinality(),
+			SizeBytes:   c.sizeBytes(),
+		}
+	}
+	return stats
+}
@@ -0,0 +1,51 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_AndCardinalityRange(t *testing.T) {
+	a := NewRoaringBitmapPosting()
+	b := NewRoaringBitmapPosting()
+
+	aIDs := []uint64{5, 10, 1 << 16, (1 << 16) + 5, (2 << 16) + 3}
+	bIDs := []uint64{10, 20, (1 << 16) + 5, (1 << 16) + 6, (2 << 16) + 3, (2 << 16) + 9}
+	for _, id := range aIDs {
+		a.Add(id)
+	}
+	for _, id := range bIDs {
+		b.Add(id)
+	}
+
+	bruteForce := func(lo, hi uint64) uint64 {
+		set := map[uint64]bool{}
+		for _, id := range bIDs {
+			set[id] = true
+		}
+		var n uint64
+		for _, id := range aIDs {
+			if id >= lo && id < hi && set[id] {
+				n++
+			}
+		}
+		return n
+	}
+
+	lo, hi := uint64(8), uint64(2<<16+4)
+	testutil.Equals(t, bruteForce(lo, hi), a.AndCardinalityRange(b, lo, hi))
+}
@@ -0,0 +1,42 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestNewRoaringRange(t *testing.T) {
+	const start, end = (1 << 16) - 5, (1 << 16) + 10
+
+	got := NewRoaringRange(start, end)
+
+	// Reference built by adding every ID one at a time, the same membership
+	// AddRange produces.
+	want := NewRoaringBitmapPosting()
+	for i := uint64(start); i < end; i++ {
+		want.Add(i)
+	}
+
+	testutil.Equals(t, iterateAll(want), iterateAll(got))
+}
+
+func BenchmarkNewRoaringRange(b *testing.B) {
+	const n = 2_000_000
+	for i := 0; i < b.N; i++ {
+		NewRoaringRange(0, n)
+	}
+}
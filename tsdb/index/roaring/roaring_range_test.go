@@ -0,0 +1,51 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_CountRange(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	ids := []uint64{1, 5, 1 << 16, (1 << 16) + 10, (2 << 16) + 4, (2 << 16) + 70000 - 1}
+	for _, id := range ids {
+		r.Add(id)
+	}
+
+	bruteForce := func(lo, hi uint64) uint64 {
+		var n uint64
+		for _, id := range ids {
+			if id >= lo && id < hi {
+				n++
+			}
+		}
+		return n
+	}
+
+	cases := []struct{ lo, hi uint64 }{
+		{0, 0},
+		{0, 1},
+		{0, 10},
+		{0, 1 << 16},
+		{1, 1<<16 + 11},
+		{0, ^uint64(0)},
+		{10, 5},
+	}
+	for _, c := range cases {
+		testutil.Equals(t, bruteForce(c.lo, c.hi), r.CountRange(c.lo, c.hi))
+	}
+}
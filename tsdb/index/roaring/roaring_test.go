@@ -0,0 +1,49 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_Optimize(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+
+	// A sparse region: few, widely-spaced values in container 0.
+	r.Add(1)
+	r.Add(1000)
+	r.Add(60000)
+
+	// A long contiguous run in container 1.
+	const runStart, runLen = uint64(1) << 16, 40000
+	for i := uint64(0); i < runLen; i++ {
+		r.Add(runStart + i)
+	}
+
+	// A dense, non-contiguous region in container 2.
+	const denseStart = uint64(2) << 16
+	for i := uint64(0); i < 40000; i += 2 {
+		r.Add(denseStart + i)
+	}
+
+	r.Optimize()
+
+	stats := r.ContainerStats()
+	testutil.Equals(t, 3, len(stats))
+	testutil.Equals(t, ContainerArray, stats[0].Type)
+	testutil.Equals(t, ContainerRun, stats[1].Type)
+	testutil.Equals(t, ContainerBitmap, stats[2].Type)
+}
@@ -0,0 +1,202 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import "sort"
+
+// run is an inclusive-start, length-encoded contiguous range: it covers
+// [start, start+length].
+type run struct {
+	start  uint16
+	length uint16
+}
+
+// runContainer stores members as a sorted, non-overlapping list of runs. It
+// is the natural representation for long contiguous ranges.
+type runContainer struct {
+	runs []run
+}
+
+func newRunContainer() *runContainer {
+	return &runContainer{}
+}
+
+func (c *runContainer) typ() ContainerType { return ContainerRun }
+
+func (c *runContainer) indexOf(v uint16) int {
+	return sort.Search(len(c.runs), func(i int) bool {
+		return uint32(c.runs[i].start)+uint32(c.runs[i].length) >= uint32(v)
+	})
+}
+
+func (c *runContainer) add(v uint16) container {
+	i := c.indexOf(v)
+	if i < len(c.runs) && c.runs[i].start <= v {
+		return c
+	}
+	// Try to extend the previous or next run, otherwise insert a new one.
+	merged := run{start: v, length: 0}
+	if i > 0 && uint32(c.runs[i-1].start)+uint32(c.runs[i-1].length)+1 == uint32(v) {
+		c.runs[i-1].length++
+		if i < len(c.runs) && uint32(c.runs[i-1].start)+uint32(c.runs[i-1].length)+1 == uint32(c.runs[i].start) {
+			c.runs[i-1].length += c.runs[i].length + 1
+			c.runs = append(c.runs[:i], c.runs[i+1:]...)
+		}
+		return c
+	}
+	if i < len(c.runs) && uint32(c.runs[i].start) == uint32(v)+1 {
+		c.runs[i].start = v
+		c.runs[i].length++
+		return c
+	}
+	c.runs = append(c.runs, run{})
+	copy(c.runs[i+1:], c.runs[i:])
+	c.runs[i] = merged
+	return c
+}
+
+func (c *runContainer) remove(v uint16) container {
+	for i, r := range c.runs {
+		lo, hi := uint32(r.start), uint32(r.start)+uint32(r.length)
+		if uint32(v) < lo || uint32(v) > hi {
+			continue
+		}
+		switch {
+		case uint32(v) == lo && uint32(v) == hi:
+			c.runs = append(c.runs[:i], c.runs[i+1:]...)
+		case uint32(v) == lo:
+			c.runs[i].start++
+			c.runs[i].length--
+		case uint32(v) == hi:
+			c.runs[i].length--
+		default:
+			left := run{start: r.start, length: uint16(uint32(v) - lo - 1)}
+			right := run{start: v + 1, length: uint16(hi - uint32(v) - 1)}
+			c.runs = append(c.runs[:i], append([]run{left, right}, c.runs[i+1:]...)...)
+		}
+		return c
+	}
+	return c
+}
+
+func (c *runContainer) contains(v uint16) bool {
+	for _, r := range c.runs {
+		if uint32(v) >= uint32(r.start) && uint32(v) <= uint32(r.start)+uint32(r.length) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *runContainer) cardinality() int {
+	n := 0
+	for _, r := range c.runs {
+		n += int(r.length) + 1
+	}
+	return n
+}
+
+func (c *runContainer) minimum() uint16 { return c.runs[0].start }
+
+func (c *runContainer) maximum() uint16 {
+	last := c.runs[len(c.runs)-1]
+	return last.start + last.length
+}
+
+func (c *runContainer) rankLE(v uint16) int {
+	rank := 0
+	for _, r := range c.runs {
+		lo, hi := uint32(r.start), uint32(r.start)+uint32(r.length)
+		if lo > uint32(v) {
+			break
+		}
+		if hi <= uint32(v) {
+			rank += int(r.length) + 1
+			continue
+		}
+		rank += int(uint32(v) - lo + 1)
+	}
+	return rank
+}
+
+func (c *runContainer) selectAt(i int) (uint16, bool) {
+	if i < 0 {
+		return 0, false
+	}
+	remaining := i
+	for _, r := range c.runs {
+		n := int(r.length) + 1
+		if remaining < n {
+			return r.start + uint16(remaining), true
+		}
+		remaining -= n
+	}
+	return 0, false
+}
+
+func (c *runContainer) clone() container {
+	runs := make([]run, len(c.runs))
+	copy(runs, c.runs)
+	return &runContainer{runs: runs}
+}
+
+func (c *runContainer) toSlice() []uint16 {
+	vals := make([]uint16, 0, c.cardinality())
+	for _, r := range c.runs {
+		for v := uint32(r.start); v <= uint32(r.start)+uint32(r.length); v++ {
+			vals = append(vals, uint16(v))
+		}
+	}
+	return vals
+}
+
+func (c *runContainer) and(o container) container {
+	res := newArrayContainer()
+	for _, v := range c.toSlice() {
+		if o.contains(v) {
+			res.vals = append(res.vals, v)
+		}
+	}
+	return res
+}
+
+func (c *runContainer) or(o container) container {
+	res := newArrayContainer()
+	for _, v := range c.toSlice() {
+		res.vals = append(res.vals, v)
+	}
+	for _, v := range o.toSlice() {
+		res.add(v)
+	}
+	return res
+}
+
+func (c *runContainer) andNot(o container) container {
+	res := newArrayContainer()
+	for _, v := range c.toSlice() {
+		if !o.contains(v) {
+			res.vals = append(res.vals, v)
+		}
+	}
+	return res
+}
+
+func (c *runContainer) sizeBytes() uint64 {
+	return uint64(len(c.runs)) * 4
+}
+
+// isContiguous reports whether the container covers exactly one run.
+func (c *runContainer) isContiguous() bool {
+	return len(c.runs) == 1
+}
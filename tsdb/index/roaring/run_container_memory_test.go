@@ -0,0 +1,83 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+// TestRunContainer_AutoSelectedForContiguousRange confirms Optimize promotes
+// a fully contiguous range to run containers, since that representation is
+// by far the smallest for this shape of data.
+func TestRunContainer_AutoSelectedForContiguousRange(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	for v := uint64(0); v < 1000000; v++ {
+		r.Add(v)
+	}
+	r.Optimize()
+
+	for _, stat := range r.ContainerStats() {
+		testutil.Equals(t, ContainerRun, stat.Type)
+	}
+}
+
+// BenchmarkContiguousRange_RunVsBitmapVsArray reports the in-memory size of
+// a fully contiguous 1M-element range under each container representation,
+// to justify auto-selecting run containers for this shape of data.
+func BenchmarkContiguousRange_RunVsBitmapVsArray(b *testing.B) {
+	const n = 1000000
+
+	b.Run("run", func(b *testing.B) {
+		r := NewRoaringRange(0, n)
+		r.RunOptimize()
+		b.ReportMetric(float64(totalSizeBytes(r)), "bytes")
+	})
+	b.Run("array", func(b *testing.B) {
+		r := NewRoaringBitmapPosting()
+		for v := uint64(0); v < n; v++ {
+			r.Add(v)
+		}
+		for i, c := range r.containers {
+			arr := newArrayContainer()
+			arr.vals = append(arr.vals, c.toSlice()...)
+			r.containers[i] = arr
+		}
+		b.ReportMetric(float64(totalSizeBytes(r)), "bytes")
+	})
+	b.Run("bitmap", func(b *testing.B) {
+		r := NewRoaringBitmapPosting()
+		for v := uint64(0); v < n; v++ {
+			r.Add(v)
+		}
+		r.Compact()
+		for i, c := range r.containers {
+			bmp := newBitmapContainer()
+			for _, v := range c.toSlice() {
+				bmp.add(v)
+			}
+			r.containers[i] = bmp
+		}
+		b.ReportMetric(float64(totalSizeBytes(r)), "bytes")
+	})
+}
+
+func totalSizeBytes(r *RoaringBitmapPosting) uint64 {
+	var n uint64
+	for _, c := range r.containers {
+		n += c.sizeBytes()
+	}
+	return n
+}
@@ -0,0 +1,38 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+// TestSelect_FirstLastOutOfRange names the three boundary cases explicitly:
+// Select skips whole containers via their cardinalities before descending
+// into the one holding the target rank.
+func TestSelect_FirstLastOutOfRange(t *testing.T) {
+	r := buildBitmap(5, 70000, 70001, 140000)
+
+	first, ok := r.Select(0)
+	testutil.Assert(t, ok, "expected Select(0) to find the first member")
+	testutil.Equals(t, uint64(5), first)
+
+	last, ok := r.Select(3)
+	testutil.Assert(t, ok, "expected Select(3) to find the last member")
+	testutil.Equals(t, uint64(140000), last)
+
+	_, ok = r.Select(4)
+	testutil.Assert(t, !ok, "expected Select past the end to report not-ok")
+}
@@ -0,0 +1,48 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import "fmt"
+
+// SelectMany maps a batch of ranks to their members in a single pass over
+// the bitmap's containers, rather than doing an independent Select per
+// rank. ranks must be sorted ascending; SelectMany returns an error
+// otherwise. Any rank at or beyond Cardinality is omitted from the result.
+func (r *RoaringBitmapPosting) SelectMany(ranks []uint64) ([]uint64, error) {
+	for i := 1; i < len(ranks); i++ {
+		if ranks[i] < ranks[i-1] {
+			return nil, fmt.Errorf("roaring: ranks must be sorted ascending, got %d after %d", ranks[i], ranks[i-1])
+		}
+	}
+
+	out := make([]uint64, 0, len(ranks))
+	var base uint64
+	ri := 0
+	for i, c := range r.containers {
+		card := uint64(c.cardinality())
+		for ri < len(ranks) && ranks[ri] < base+card {
+			low, ok := c.selectAt(int(ranks[ri] - base))
+			if !ok {
+				break
+			}
+			out = append(out, joinBits(r.keys[i], low))
+			ri++
+		}
+		base += card
+		if ri >= len(ranks) {
+			break
+		}
+	}
+	return out, nil
+}
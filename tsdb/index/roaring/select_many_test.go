@@ -0,0 +1,50 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_SelectMany(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	for v := uint64(0); v < 10; v++ {
+		r.Add(v * 7)
+	}
+	r.Add(1 << 17)
+
+	ranks := []uint64{0, 5, 9}
+
+	var want []uint64
+	for _, n := range ranks {
+		v, ok := r.Select(int(n))
+		testutil.Assert(t, ok, "expected Select(%d) to find a value", n)
+		want = append(want, v)
+	}
+
+	got, err := r.SelectMany(ranks)
+	testutil.Ok(t, err)
+	testutil.Equals(t, want, got)
+}
+
+func TestRoaringBitmapPosting_SelectMany_Unsorted(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	r.Add(1)
+	r.Add(2)
+
+	_, err := r.SelectMany([]uint64{1, 0})
+	testutil.NotOk(t, err)
+}
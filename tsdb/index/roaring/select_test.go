@@ -0,0 +1,37 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_Select(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	members := []uint64{5, 10, 1 << 16, (1 << 16) + 3, 1 << 17}
+	for _, v := range members {
+		r.Add(v)
+	}
+
+	for n, want := range members {
+		got, ok := r.Select(n)
+		testutil.Assert(t, ok, "expected Select(%d) to find a value", n)
+		testutil.Equals(t, want, got)
+	}
+
+	_, ok := r.Select(len(members))
+	testutil.Assert(t, !ok, "expected Select past the end to fail")
+}
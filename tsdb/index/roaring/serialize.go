@@ -0,0 +1,182 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+// wireVersion identifies the on-disk layout written by WriteTo/MarshalBinary.
+// Multi-byte integers are big-endian, matching the rest of the tsdb index.
+const wireVersion = 1
+
+func encodeContainer(w io.Writer, key uint64, c container) error {
+	var hdr [13]byte
+	binary.BigEndian.PutUint64(hdr[0:8], key)
+	hdr[8] = byte(c.typ())
+
+	var payload []byte
+	switch cc := c.(type) {
+	case *arrayContainer:
+		payload = make([]byte, len(cc.vals)*2)
+		for i, v := range cc.vals {
+			binary.BigEndian.PutUint16(payload[i*2:], v)
+		}
+	case *bitmapContainer:
+		payload = make([]byte, len(cc.words)*8)
+		for i, word := range cc.words {
+			binary.BigEndian.PutUint64(payload[i*8:], word)
+		}
+	case *runContainer:
+		payload = make([]byte, len(cc.runs)*4)
+		for i, r := range cc.runs {
+			binary.BigEndian.PutUint16(payload[i*4:], r.start)
+			binary.BigEndian.PutUint16(payload[i*4+2:], r.length)
+		}
+	default:
+		return fmt.Errorf("roaring: unknown container type %T", c)
+	}
+
+	binary.BigEndian.PutUint32(hdr[9:13], uint32(len(payload)))
+	if _, err := w.Write(hdr[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+func decodeContainer(r io.Reader) (key uint64, c container, err error) {
+	var hdr [13]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	key = binary.BigEndian.Uint64(hdr[0:8])
+	typ := ContainerType(hdr[8])
+	n := binary.BigEndian.Uint32(hdr[9:13])
+
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+
+	switch typ {
+	case ContainerArray:
+		ac := newArrayContainer()
+		ac.vals = make([]uint16, len(payload)/2)
+		for i := range ac.vals {
+			ac.vals[i] = binary.BigEndian.Uint16(payload[i*2:])
+		}
+		c = ac
+	case ContainerBitmap:
+		bc := newBitmapContainer()
+		for i := range bc.words {
+			bc.words[i] = binary.BigEndian.Uint64(payload[i*8:])
+		}
+		bc.card = 0
+		for _, word := range bc.words {
+			bc.card += bits.OnesCount64(word)
+		}
+		c = bc
+	case ContainerRun:
+		rc := newRunContainer()
+		rc.runs = make([]run, len(payload)/4)
+		for i := range rc.runs {
+			rc.runs[i].start = binary.BigEndian.Uint16(payload[i*4:])
+			rc.runs[i].length = binary.BigEndian.Uint16(payload[i*4+2:])
+		}
+		c = rc
+	default:
+		return 0, nil, fmt.Errorf("roaring: unknown container type %d", typ)
+	}
+	return key, c, nil
+}
+
+// WriteTo streams the bitmap's wire representation to w, avoiding a giant
+// intermediate buffer for large bitmaps. The format matches MarshalBinary.
+func (r *RoaringBitmapPosting) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+
+	if err := binary.Write(cw, binary.BigEndian, uint8(wireVersion)); err != nil {
+		return cw.n, err
+	}
+	if err := binary.Write(cw, binary.BigEndian, uint32(len(r.containers))); err != nil {
+		return cw.n, err
+	}
+	for i, c := range r.containers {
+		if err := encodeContainer(cw, r.keys[i], c); err != nil {
+			return cw.n, err
+		}
+	}
+	return cw.n, nil
+}
+
+// ReadFrom replaces r's contents with the bitmap streamed from rd, as
+// written by WriteTo.
+func (r *RoaringBitmapPosting) ReadFrom(rd io.Reader) (int64, error) {
+	r.checkMutable()
+	cr := &countingReader{r: rd}
+
+	var version uint8
+	if err := binary.Read(cr, binary.BigEndian, &version); err != nil {
+		return cr.n, err
+	}
+	if version != wireVersion {
+		return cr.n, fmt.Errorf("roaring: unsupported wire version %d", version)
+	}
+	var count uint32
+	if err := binary.Read(cr, binary.BigEndian, &count); err != nil {
+		return cr.n, err
+	}
+
+	keys := make([]uint64, count)
+	containers := make([]container, count)
+	for i := uint32(0); i < count; i++ {
+		key, c, err := decodeContainer(cr)
+		if err != nil {
+			return cr.n, err
+		}
+		keys[i] = key
+		containers[i] = c
+	}
+	r.cardCacheValid = false
+	r.treeCacheValid = false
+	r.keys = keys
+	r.containers = containers
+	return cr.n, nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
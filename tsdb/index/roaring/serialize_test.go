@@ -0,0 +1,69 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+// longPostings2Shaped builds a multi-container bitmap modeled on the
+// LongPostings2 benchmark fixture in tsdb/index/postings_test.go, scaled
+// down to a size that is fast to serialize in a unit test.
+func longPostings2Shaped() *RoaringBitmapPosting {
+	r := NewRoaringBitmapPosting()
+	for i := uint64(0); i < 125000; i++ {
+		r.Add(i)
+	}
+	for i := uint64(75000); i < 125000; i++ {
+		r.Add(i)
+	}
+	for i := uint64(90000); i < 200000; i++ {
+		r.Add(i)
+	}
+	for i := uint64(100000); i < 120000; i++ {
+		r.Add(i)
+	}
+	return r
+}
+
+func TestRoaringBitmapPosting_WriteReadRoundTrip(t *testing.T) {
+	r := longPostings2Shaped()
+	r.Optimize()
+
+	var buf bytes.Buffer
+	n, err := r.WriteTo(&buf)
+	testutil.Ok(t, err)
+	testutil.Equals(t, int64(buf.Len()), n)
+
+	got := NewRoaringBitmapPosting()
+	n2, err := got.ReadFrom(&buf)
+	testutil.Ok(t, err)
+	testutil.Equals(t, n, n2)
+
+	want := iterateAll(r)
+	have := iterateAll(got)
+	testutil.Equals(t, want, have)
+}
+
+func iterateAll(r *RoaringBitmapPosting) []uint64 {
+	it := NewRoaringBitmapIterator(r)
+	var out []uint64
+	for it.Next() {
+		out = append(out, it.At())
+	}
+	return out
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// And returns a new bitmap holding the intersection of a and b, computed
+// container by container via each container's own and method rather than
+// through the generic Postings iterator combinators.
+func And(a, b *RoaringBitmapPosting) *RoaringBitmapPosting {
+	dst := NewRoaringBitmapPosting()
+	ai, bi := 0, 0
+	for ai < len(a.keys) && bi < len(b.keys) {
+		switch {
+		case a.keys[ai] < b.keys[bi]:
+			ai++
+		case a.keys[ai] > b.keys[bi]:
+			bi++
+		default:
+			if c := a.containers[ai].and(b.containers[bi]); c.cardinality() > 0 {
+				dst.keys = append(dst.keys, a.keys[ai])
+				dst.containers = append(dst.containers, c)
+			}
+			ai++
+			bi++
+		}
+	}
+	return dst
+}
+
+// Or returns a new bitmap holding the union of a and b, computed container
+// by container via each container's own or method where both bitmaps have
+// a container for the same key, and by cloning whichever side has the only
+// container for keys that appear in just one of them.
+func Or(a, b *RoaringBitmapPosting) *RoaringBitmapPosting {
+	dst := NewRoaringBitmapPosting()
+	ai, bi := 0, 0
+	for ai < len(a.keys) || bi < len(b.keys) {
+		switch {
+		case bi >= len(b.keys) || (ai < len(a.keys) && a.keys[ai] < b.keys[bi]):
+			dst.keys = append(dst.keys, a.keys[ai])
+			dst.containers = append(dst.containers, a.containers[ai].clone())
+			ai++
+		case ai >= len(a.keys) || b.keys[bi] < a.keys[ai]:
+			dst.keys = append(dst.keys, b.keys[bi])
+			dst.containers = append(dst.containers, b.containers[bi].clone())
+			bi++
+		default:
+			dst.keys = append(dst.keys, a.keys[ai])
+			dst.containers = append(dst.containers, a.containers[ai].or(b.containers[bi]))
+			ai++
+			bi++
+		}
+	}
+	return dst
+}
+
+// AndNot returns a new bitmap holding every member of a that is not also a
+// member of b, computed container by container via each container's own
+// andNot method.
+func AndNot(a, b *RoaringBitmapPosting) *RoaringBitmapPosting {
+	dst := NewRoaringBitmapPosting()
+	ai, bi := 0, 0
+	for ai < len(a.keys) {
+		for bi < len(b.keys) && b.keys[bi] < a.keys[ai] {
+			bi++
+		}
+		if bi < len(b.keys) && b.keys[bi] == a.keys[ai] {
+			if c := a.containers[ai].andNot(b.containers[bi]); c.cardinality() > 0 {
+				dst.keys = append(dst.keys, a.keys[ai])
+				dst.containers = append(dst.containers, c)
+			}
+		} else {
+			dst.keys = append(dst.keys, a.keys[ai])
+			dst.containers = append(dst.containers, a.containers[ai].clone())
+		}
+		ai++
+	}
+	return dst
+}
@@ -0,0 +1,41 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestAnd(t *testing.T) {
+	a := buildBitmap(1, 2, 70000)
+	b := buildBitmap(2, 3, 70000, 70001)
+
+	testutil.Equals(t, []uint64{2, 70000}, iterateAll(And(a, b)))
+}
+
+func TestOr(t *testing.T) {
+	a := buildBitmap(1, 2, 70000)
+	b := buildBitmap(2, 3, 70000, 70001)
+
+	testutil.Equals(t, []uint64{1, 2, 3, 70000, 70001}, iterateAll(Or(a, b)))
+}
+
+func TestAndNot(t *testing.T) {
+	a := buildBitmap(1, 2, 70000, 70001)
+	b := buildBitmap(2, 70000)
+
+	testutil.Equals(t, []uint64{1, 70001}, iterateAll(AndNot(a, b)))
+}
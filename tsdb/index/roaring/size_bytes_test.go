@@ -0,0 +1,60 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+// TestRoaringBitmapPosting_SizeBytes_GrowsMonotonically adds elements
+// spread across several containers, some of which cross the
+// array-to-bitmap conversion threshold, and checks that SizeBytes never
+// decreases as membership grows.
+func TestRoaringBitmapPosting_SizeBytes_GrowsMonotonically(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	prev := r.SizeBytes()
+	testutil.Equals(t, uint64(0), prev)
+
+	for container := uint64(0); container < 4; container++ {
+		base := container << 16
+		for i := uint64(0); i < 20000; i++ {
+			r.Add(base + i*3)
+
+			size := r.SizeBytes()
+			testutil.Assert(t, size >= prev, "expected SizeBytes to never decrease, got %d after %d", size, prev)
+			prev = size
+		}
+	}
+}
+
+// TestRoaringBitmapPosting_SizeBytes_ReflectsContainerType confirms
+// SizeBytes reports the footprint of the container representation actually
+// in use, not a worst-case estimate.
+func TestRoaringBitmapPosting_SizeBytes_ReflectsContainerType(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	r.Add(1)
+	r.Add(2)
+	r.Add(3)
+
+	testutil.Equals(t, ContainerArray, r.ContainerStats()[0].Type)
+	testutil.Equals(t, uint64(8+6), r.SizeBytes())
+
+	for i := uint64(0); i < uint64(ArrayMaxCardinality)+1; i++ {
+		r.Add(i)
+	}
+	testutil.Equals(t, ContainerBitmap, r.ContainerStats()[0].Type)
+	testutil.Equals(t, uint64(8)+uint64(bitmapWords)*8, r.SizeBytes())
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// SplitAt divides the bitmap into two around pivot: low holds every member
+// < pivot and high holds every member >= pivot. Whole containers on either
+// side of pivot's container are moved across without touching their
+// members; only the single boundary container, if any, is split member by
+// member. r is left unmodified.
+func (r *RoaringBitmapPosting) SplitAt(pivot uint64) (low, high *RoaringBitmapPosting) {
+	low = NewRoaringBitmapPosting()
+	high = NewRoaringBitmapPosting()
+
+	pivotKey := highBits(pivot)
+	pivotLow := lowBits(pivot)
+
+	for i, key := range r.keys {
+		switch {
+		case key < pivotKey:
+			low.keys = append(low.keys, key)
+			low.containers = append(low.containers, r.containers[i].clone())
+		case key > pivotKey:
+			high.keys = append(high.keys, key)
+			high.containers = append(high.containers, r.containers[i].clone())
+		default:
+			loC := newArrayContainer()
+			hiC := newArrayContainer()
+			for _, v := range r.containers[i].toSlice() {
+				if v < pivotLow {
+					loC.vals = append(loC.vals, v)
+				} else {
+					hiC.vals = append(hiC.vals, v)
+				}
+			}
+			if loC.cardinality() > 0 {
+				low.keys = append(low.keys, key)
+				low.containers = append(low.containers, container(loC))
+			}
+			if hiC.cardinality() > 0 {
+				high.keys = append(high.keys, key)
+				high.containers = append(high.containers, container(hiC))
+			}
+		}
+	}
+	return low, high
+}
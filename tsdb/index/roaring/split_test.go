@@ -0,0 +1,42 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_SplitAt(t *testing.T) {
+	r := buildBitmap(1, 2, 5, 70000, 70001, 70002, 140000)
+
+	low, high := r.SplitAt(70001)
+	testutil.Equals(t, []uint64{1, 2, 5, 70000}, iterateAll(low))
+	testutil.Equals(t, []uint64{70001, 70002, 140000}, iterateAll(high))
+
+	// The source bitmap must be untouched.
+	testutil.Equals(t, []uint64{1, 2, 5, 70000, 70001, 70002, 140000}, iterateAll(r))
+
+	// Reassembly via Or must reproduce the original.
+	testutil.Equals(t, []uint64{1, 2, 5, 70000, 70001, 70002, 140000}, iterateAll(Or(low, high)))
+}
+
+func TestRoaringBitmapPosting_SplitAt_WholeContainerBoundary(t *testing.T) {
+	r := buildBitmap(1, 2, 70000, 140000)
+
+	low, high := r.SplitAt(70000)
+	testutil.Equals(t, []uint64{1, 2}, iterateAll(low))
+	testutil.Equals(t, []uint64{70000, 140000}, iterateAll(high))
+}
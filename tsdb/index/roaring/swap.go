@@ -0,0 +1,27 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// Swap exchanges the contents of r and other in O(1), without copying any
+// container. It is meant for pipelines that build a new bitmap and then
+// replace the old one: swap the freshly built bitmap into the live
+// variable and let the old contents be reused or discarded by the caller.
+func (r *RoaringBitmapPosting) Swap(other *RoaringBitmapPosting) {
+	r.checkMutable()
+	other.checkMutable()
+	r.cardCacheValid, other.cardCacheValid = false, false
+	r.treeCacheValid, other.treeCacheValid = false, false
+	r.keys, other.keys = other.keys, r.keys
+	r.containers, other.containers = other.containers, r.containers
+}
@@ -0,0 +1,36 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_Swap(t *testing.T) {
+	a := buildBitmap(1, 2, 3)
+	b := buildBitmap(10, 20, 30)
+
+	a.Swap(b)
+
+	testutil.Equals(t, []uint64{10, 20, 30}, iterateAll(a))
+	testutil.Equals(t, []uint64{1, 2, 3}, iterateAll(b))
+
+	// Both remain independently usable after the swap.
+	a.Add(40)
+	b.Add(4)
+	testutil.Equals(t, []uint64{10, 20, 30, 40}, iterateAll(a))
+	testutil.Equals(t, []uint64{1, 2, 3, 4}, iterateAll(b))
+}
@@ -1,11 +1,18 @@
 package roaring
 
-import "sort"
+import (
+	"bytes"
+	"sort"
+)
 
-type T = int
+// T is the value the tree indexes: a roaring container keyed by its high
+// bits, so the tree can resolve RoaringBitmapPosting's per-range container
+// lookup.
+type T = container
 
 type tree struct {
-	head *node
+	head  *node
+	count int
 }
 
 type node struct {
@@ -15,10 +22,34 @@ type node struct {
 	data  *T
 }
 
+// iterator walks a tree's entries in ascending key order, starting from
+// wherever iterFrom positioned it. Entries are collected up front rather
+// than descended lazily node by node, since the tree is small enough that
+// this is simpler to get right and just as correct.
 type iterator struct {
-	key     []byte
-	stack   []*node
-	current *node
+	entries []treeEntry
+	pos     int
+}
+
+type treeEntry struct {
+	key []byte
+	val *T
+}
+
+// Next advances the iterator and reports whether another entry was found.
+func (it *iterator) Next() bool {
+	it.pos++
+	return it.pos < len(it.entries)
+}
+
+// Key returns the key at the iterator's current position.
+func (it *iterator) Key() []byte {
+	return it.entries[it.pos].key
+}
+
+// Value returns the value at the iterator's current position.
+func (it *iterator) Value() *T {
+	return it.entries[it.pos].val
 }
 
 func newTree(t *tree) *tree {
@@ -33,14 +64,23 @@ func (t *tree) insert(key []byte, val *T) {
 	if t.head == nil {
 		t.head = _find(t.head, key, true)
 		t.head.data = val
+		t.count++
 		return
 	}
 
 	var node = _find(t.head, key, true)
+	if node.data == nil {
+		t.count++
+	}
 	node.data = val
 	return
 }
 
+// len reports how many keys are currently stored in the tree.
+func (t *tree) len() int {
+	return t.count
+}
+
 func (t *tree) find(key []byte) *T {
 	var val = _find(t.head, key, false)
 
@@ -50,50 +90,244 @@ func (t *tree) find(key []byte) *T {
 	return val.data
 }
 
-func (*tree) insertBatch(key [][]byte, val []*T)            {}
-func (*tree) iterFrom(key []byte, iter *iterator) *iterator { return iter }
+// delete removes key from the tree, reporting whether it was present. Any
+// node left with no data and a single remaining child is merged with that
+// child, the inverse of the split _find performs on insert.
+func (t *tree) delete(key []byte) bool {
+	if t.head == nil {
+		return false
+	}
+	deleted, empty := deleteNode(t.head, nil, key)
+	if !deleted {
+		return false
+	}
+	if empty {
+		t.head = nil
+	} else {
+		collapseIfNeeded(t.head)
+	}
+	t.count--
+	return true
+}
+
+// deleteNode removes key from n's subtree, where full is the key already
+// consumed by n's ancestors. It reports whether key was found and deleted,
+// and whether n itself is now empty (no data, no children) and should be
+// spliced out of its parent's child list.
+func deleteNode(n *node, full []byte, key []byte) (deleted, empty bool) {
+	full = append(append([]byte(nil), full...), n.key...)
+
+	if bytes.Equal(full, key) {
+		if n.data == nil {
+			return false, false
+		}
+		n.data = nil
+		return true, len(n.child) == 0
+	}
+
+	if len(key) <= len(full) || !bytes.HasPrefix(key, full) {
+		return false, false
+	}
+
+	for i := range n.child {
+		c := &n.child[i]
+		if !bytes.HasPrefix(key[len(full):], c.key) {
+			continue
+		}
+
+		d, childEmpty := deleteNode(c, full, key)
+		if !d {
+			return false, false
+		}
+		if childEmpty {
+			n.child = append(n.child[:i], n.child[i+1:]...)
+		} else {
+			collapseIfNeeded(c)
+		}
+		return true, n.data == nil && len(n.child) == 0
+	}
+	return false, false
+}
+
+// collapseIfNeeded merges n with its sole child when n holds no data of its
+// own, keeping the tree as compact as _find's split logic assumes.
+func collapseIfNeeded(n *node) {
+	if n.data == nil && len(n.child) == 1 {
+		only := n.child[0]
+		n.key = append(n.key, only.key...)
+		n.data = only.data
+		n.child = only.child
+	}
+}
+
+// rangeScan visits every key in [lo, hi) in ascending order, calling fn for
+// each, and stops as soon as fn returns false. It reuses collectEntries
+// rather than pruning subtrees outside the range during descent, since the
+// tree is small enough for that to be simpler to get right and just as
+// correct.
+func (t *tree) rangeScan(lo, hi []byte, fn func(key []byte, val *T) bool) {
+	if t.head == nil {
+		return
+	}
+	var entries []treeEntry
+	collectEntries(t.head, nil, &entries)
+
+	i := sort.Search(len(entries), func(i int) bool {
+		return bytes.Compare(entries[i].key, lo) >= 0
+	})
+	for ; i < len(entries) && bytes.Compare(entries[i].key, hi) < 0; i++ {
+		if !fn(entries[i].key, entries[i].val) {
+			return
+		}
+	}
+}
+
+// keysWithPrefix returns every stored key that begins with prefix, in
+// sorted order, or nil if none match.
+func (t *tree) keysWithPrefix(prefix []byte) [][]byte {
+	if t.head == nil {
+		return nil
+	}
+	var entries []treeEntry
+	collectEntries(t.head, nil, &entries)
+
+	i := sort.Search(len(entries), func(i int) bool {
+		return bytes.Compare(entries[i].key, prefix) >= 0
+	})
+
+	var out [][]byte
+	for ; i < len(entries) && bytes.HasPrefix(entries[i].key, prefix); i++ {
+		out = append(out, entries[i].key)
+	}
+	return out
+}
+
+// insertBatch inserts many key/value pairs at once, producing the same
+// tree as calling insert for each pair individually. Sorting the pairs by
+// key first means _appendClientOrdered sees mostly-ascending keys, so each
+// insert tends to append at the end of a child slice instead of shifting
+// it to make room in the middle.
+func (t *tree) insertBatch(key [][]byte, val []*T) {
+	if len(key) != len(val) {
+		panic("roaring: insertBatch called with mismatched key/value slice lengths")
+	}
+	idx := make([]int, len(key))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(a, b int) bool {
+		return string(key[idx[a]]) < string(key[idx[b]])
+	})
+	for _, i := range idx {
+		t.insert(key[i], val[i])
+	}
+}
+
+// iterFrom positions iter at the first stored key >= key, ready for
+// ordered forward traversal via iter.Next. Passing a nil iter allocates a
+// fresh one.
+func (t *tree) iterFrom(key []byte, iter *iterator) *iterator {
+	if iter == nil {
+		iter = &iterator{}
+	}
+	var entries []treeEntry
+	if t.head != nil {
+		collectEntries(t.head, nil, &entries)
+	}
+	i := sort.Search(len(entries), func(i int) bool {
+		return bytes.Compare(entries[i].key, key) >= 0
+	})
+	iter.entries = entries[i:]
+	iter.pos = -1
+	return iter
+}
+
+// collectEntries appends every key/value pair in n's subtree to out, in
+// ascending order. Because n.key (if n holds data) is always a prefix of
+// every key reachable through n.child, and a string sorts before any
+// longer string it prefixes, visiting n itself before its children (which
+// are already kept in sorted order by _appendClientOrdered) yields the
+// whole subtree in sorted order.
+func collectEntries(n *node, prefix []byte, out *[]treeEntry) {
+	full := append(append([]byte(nil), prefix...), n.key...)
+	if n.data != nil {
+		*out = append(*out, treeEntry{key: full, val: n.data})
+	}
+	for i := range n.child {
+		collectEntries(&n.child[i], full, out)
+	}
+}
 
 func _find(root *node, key []byte, create bool) *node {
 	// the key suffix on sub node
 	var keyOnSubNode = key
 
-	for root != nil && root.data == nil && len(key) >= len(root.key) {
+	for root != nil && len(key) >= len(root.key) {
 		// check if the new root contains this key
+		keyBeforeDescent := key
 		keyOnSubNode = key[_prefixLength(root.key, key):]
 		if len(keyOnSubNode) == 0 {
-			// return the new root
-			goto END
+			// The loop guard above already ensures len(key) >= len(root.key),
+			// so an empty remainder here can only mean key matched root.key
+			// exactly, never that key terminated partway through it.
+			return root
 		}
 
-		for _, i := range root.child {
+		var matched = false
+		for idx := range root.child {
 			// check if the new root's child contains this key
-			if _prefixLength(i.key, keyOnSubNode) == 0 {
+			if _prefixLength(root.child[idx].key, keyOnSubNode) == 0 {
 				// not contain, check next
 				continue
 			}
 
-			// key contained on the child, move to the next node
-			root = &i
+			// key contained on the child, move to the next node. Taking
+			// the address of the slice element itself (rather than a copy
+			// of the range variable) so mutations made through the
+			// returned node, and further descent below it, affect the
+			// real tree.
+			root = &root.child[idx]
 			key = keyOnSubNode
+			matched = true
 			break
 		}
 
-		// all child not contained this key, return the new root
-		goto END
+		if !matched {
+			// No child extends past root. Undo the reduction above so the
+			// fallback below compares root.key against the key exactly as
+			// it stood on entry to this iteration, rather than against the
+			// already-subtracted remainder - otherwise a remainder that
+			// happens to share a byte run with root.key's own tail (e.g.
+			// root.key "aa", key "aaa") would be misread as key
+			// terminating inside root.key.
+			keyOnSubNode = keyBeforeDescent
+			break
+		}
 	}
 
-END:
 	if root == nil {
 		if create {
 			return &node{key: keyOnSubNode}
-		} else {
-			return nil
 		}
+		return nil
 	}
 
 	var prefix = _prefixLength(root.key, keyOnSubNode)
 
 	if prefix == len(keyOnSubNode) {
+		if create && prefix < len(root.key) {
+			// key terminates partway through root.key: split root's key
+			// into the matched prefix, which the caller will attach the
+			// new value to, and a child holding root's own untouched
+			// remainder, along with the data and children root already
+			// had. Without this, returning root as-is would let the
+			// caller's new value overwrite the key root.key actually
+			// names.
+			child := node{key: root.key[prefix:], data: root.data, child: root.child}
+			root.key = root.key[:prefix]
+			root.data = nil
+			root.child = []node{child}
+		}
 		return root
 	}
 
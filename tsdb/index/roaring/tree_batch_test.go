@@ -0,0 +1,88 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestTree_InsertBatch_MatchesRepeatedInsert(t *testing.T) {
+	keys := [][]byte{{1}, {2}, {3}, {5}}
+	vals := make([]*T, len(keys))
+	for i := range vals {
+		v := testContainer(i)
+		vals[i] = &v
+	}
+
+	batch := &tree{}
+	batch.insertBatch(keys, vals)
+
+	sequential := &tree{}
+	for i, k := range keys {
+		sequential.insert(k, vals[i])
+	}
+
+	// Compare both by their sorted entry order and by looking every key
+	// back up through find(), so a regression in either insert path would
+	// show up as a wrong value, not just a wrong key order.
+	var gotBatch, gotSequential [][]byte
+	for it := batch.iterFrom(nil, nil); it.Next(); {
+		gotBatch = append(gotBatch, it.Key())
+	}
+	for it := sequential.iterFrom(nil, nil); it.Next(); {
+		gotSequential = append(gotSequential, it.Key())
+	}
+	testutil.Equals(t, gotSequential, gotBatch)
+
+	for i, k := range keys {
+		testutil.Equals(t, vals[i], batch.find(k))
+		testutil.Equals(t, vals[i], sequential.find(k))
+	}
+}
+
+func TestTree_InsertBatch_PanicsOnLengthMismatch(t *testing.T) {
+	defer func() {
+		testutil.Assert(t, recover() != nil, "expected insertBatch to panic on mismatched slice lengths")
+	}()
+	(&tree{}).insertBatch([][]byte{{1}}, nil)
+}
+
+func BenchmarkInsertBatch_100k(b *testing.B) {
+	const n = 100000
+	keys := make([][]byte, n)
+	vals := make([]*T, n)
+	for i := 0; i < n; i++ {
+		keys[i] = []byte(fmt.Sprintf("key-%08d", i))
+		v := testContainer(i)
+		vals[i] = &v
+	}
+
+	b.Run("Batch", func(bench *testing.B) {
+		for i := 0; i < bench.N; i++ {
+			tr := &tree{}
+			tr.insertBatch(keys, vals)
+		}
+	})
+	b.Run("Loop", func(bench *testing.B) {
+		for i := 0; i < bench.N; i++ {
+			tr := &tree{}
+			for j := range keys {
+				tr.insert(keys[j], vals[j])
+			}
+		}
+	})
+}
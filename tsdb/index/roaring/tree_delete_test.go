@@ -0,0 +1,61 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestTree_Delete(t *testing.T) {
+	t.Run("merges the remaining sibling into its parent", func(t *testing.T) {
+		tr := &tree{head: &node{
+			key: []byte{1, 2},
+			child: []node{
+				{key: []byte{2}, data: pointer(testContainer(1))},
+				{key: []byte{3}, data: pointer(testContainer(2))},
+			},
+		}}
+
+		testutil.Assert(t, tr.delete([]byte{1, 2, 2}), "expected key to be deleted")
+		testutil.Equals(t, []byte{1, 2, 3}, tr.head.key)
+		testutil.Equals(t, pointer(testContainer(2)), tr.head.data)
+		testutil.Equals(t, 0, len(tr.head.child))
+	})
+
+	t.Run("removing the head's last value empties the tree", func(t *testing.T) {
+		tr := &tree{head: &node{key: []byte{1, 2}, data: pointer(testContainer(1))}}
+
+		testutil.Assert(t, tr.delete([]byte{1, 2}), "expected key to be deleted")
+		testutil.Assert(t, tr.head == nil, "expected the tree to be empty")
+	})
+
+	t.Run("deleting a missing key reports false and leaves the tree untouched", func(t *testing.T) {
+		tr := &tree{head: &node{
+			key: []byte{1, 2},
+			child: []node{
+				{key: []byte{2}, data: pointer(testContainer(1))},
+			},
+		}}
+
+		testutil.Assert(t, !tr.delete([]byte{9, 9}), "expected delete of a missing key to report false")
+		testutil.Equals(t, []byte{1, 2}, tr.head.key)
+		testutil.Equals(t, 1, len(tr.head.child))
+	})
+
+	t.Run("empty tree", func(t *testing.T) {
+		testutil.Assert(t, !(&tree{}).delete([]byte{1}), "expected delete on an empty tree to report false")
+	})
+}
@@ -0,0 +1,33 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_ContainsViaTree(t *testing.T) {
+	bm := buildBitmap(1, 2, 70000, 140000)
+
+	for _, v := range []uint64{1, 2, 70000, 140000, 3, 70001, 200000} {
+		testutil.Equals(t, bm.Contains(v), bm.containsViaTree(v))
+	}
+}
+
+func TestRoaringBitmapPosting_ContainsViaTree_Empty(t *testing.T) {
+	bm := NewRoaringBitmapPosting()
+	testutil.Assert(t, !bm.containsViaTree(1), "expected an empty bitmap to contain nothing")
+}
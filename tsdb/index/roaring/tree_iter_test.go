@@ -0,0 +1,43 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestTree_IterFrom(t *testing.T) {
+	tr := &tree{}
+	for _, k := range [][]byte{{1, 2}, {1, 3}, {1, 2, 4}, {5}, {1, 2, 4, 5}} {
+		v := testContainer(0)
+		tr.insert(k, &v)
+	}
+
+	it := tr.iterFrom(nil, nil)
+	var got [][]byte
+	for it.Next() {
+		got = append(got, it.Key())
+	}
+	for i := 1; i < len(got); i++ {
+		testutil.Assert(t, string(got[i-1]) < string(got[i]), "expected keys in sorted order, got %v then %v", got[i-1], got[i])
+	}
+
+	// Seeking to a key that falls between two children should land on the
+	// next one, skipping everything strictly smaller.
+	it2 := tr.iterFrom([]byte{1, 2, 5}, nil)
+	testutil.Assert(t, it2.Next(), "expected at least one key >= {1, 2, 5}")
+	testutil.Assert(t, string(it2.Key()) >= string([]byte{1, 2, 5}), "expected %v >= {1, 2, 5}", it2.Key())
+}
@@ -0,0 +1,42 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestTree_KeysWithPrefix(t *testing.T) {
+	var head *node
+	for i, k := range [][]byte{{1, 2}, {1, 2, 3}, {1, 2, 4}, {1, 5}, {9}} {
+		v := testContainer(i)
+		head = insertNode(head, k, &v)
+	}
+	tr := &tree{head: head}
+
+	// Prefix {1, 2} lands exactly on a compressed edge boundary.
+	testutil.Equals(t, [][]byte{{1, 2}, {1, 2, 3}, {1, 2, 4}}, tr.keysWithPrefix([]byte{1, 2}))
+
+	// Prefix {1} lands in the middle of the {1, 2}/{1, 5} split.
+	testutil.Equals(t, [][]byte{{1, 2}, {1, 2, 3}, {1, 2, 4}, {1, 5}}, tr.keysWithPrefix([]byte{1}))
+
+	// No key shares this prefix.
+	testutil.Assert(t, tr.keysWithPrefix([]byte{2}) == nil, "expected no matches for an unused prefix")
+}
+
+func TestTree_KeysWithPrefix_EmptyTree(t *testing.T) {
+	testutil.Assert(t, (&tree{}).keysWithPrefix([]byte{1}) == nil, "expected no matches on an empty tree")
+}
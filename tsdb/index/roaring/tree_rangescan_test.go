@@ -0,0 +1,97 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestTree_RangeScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+
+	seen := map[string]bool{}
+	var keys [][]byte
+	for len(keys) < 300 {
+		k := make([]byte, 1+rng.Intn(4))
+		for i := range k {
+			k[i] = byte(rng.Intn(8))
+		}
+		if seen[string(k)] {
+			continue
+		}
+		seen[string(k)] = true
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+
+	var head *node
+	for i, k := range keys {
+		v := testContainer(i)
+		head = insertNode(head, k, &v)
+	}
+	tr := &tree{head: head}
+
+	cases := []struct{ lo, hi []byte }{
+		{nil, []byte{8}},
+		{[]byte{3}, []byte{6}},
+		{[]byte{0, 0}, []byte{2}},
+		{[]byte{7}, []byte{7}},
+	}
+	for _, c := range cases {
+		var want [][]byte
+		for _, k := range keys {
+			if bytes.Compare(k, c.lo) >= 0 && bytes.Compare(k, c.hi) < 0 {
+				want = append(want, k)
+			}
+		}
+
+		var got [][]byte
+		tr.rangeScan(c.lo, c.hi, func(key []byte, val *T) bool {
+			got = append(got, append([]byte(nil), key...))
+			return true
+		})
+		testutil.Equals(t, want, got)
+	}
+}
+
+func TestTree_RangeScan_StopsEarly(t *testing.T) {
+	tr := &tree{}
+	var head *node
+	for i, k := range [][]byte{{1}, {2}, {3}, {4}} {
+		v := testContainer(i)
+		head = insertNode(head, k, &v)
+	}
+	tr.head = head
+
+	var got [][]byte
+	tr.rangeScan(nil, []byte{9}, func(key []byte, val *T) bool {
+		got = append(got, append([]byte(nil), key...))
+		return len(got) < 2
+	})
+	testutil.Equals(t, [][]byte{{1}, {2}}, got)
+}
+
+func TestTree_RangeScan_EmptyTree(t *testing.T) {
+	called := false
+	(&tree{}).rangeScan(nil, []byte{9}, func(key []byte, val *T) bool {
+		called = true
+		return true
+	})
+	testutil.Assert(t, !called, "expected rangeScan over an empty tree to call fn zero times")
+}
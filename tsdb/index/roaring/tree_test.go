@@ -9,6 +9,15 @@ func pointer(v T) *T {
 	return &v
 }
 
+// testContainer returns a distinct container value for each n, so tree
+// tests can tell stored values apart by identity-of-content rather than
+// needing a comparable placeholder type.
+func testContainer(n int) T {
+	c := newArrayContainer()
+	c.vals = []uint16{uint16(n)}
+	return c
+}
+
 func TestNodeFind(t *testing.T) {
 	t.Run("empty", func(t *testing.T) {
 		testutil.Equals(t, _find(nil, []byte{1, 2}, false), (*node)(nil))
@@ -19,8 +28,8 @@ func TestNodeFind(t *testing.T) {
 		var root = &node{
 			key: []byte{1, 2},
 			child: []node{
-				{key: []byte{2}, data: pointer(1)},
-				{key: []byte{3}, data: pointer(2)},
+				{key: []byte{2}, data: pointer(testContainer(1))},
+				{key: []byte{3}, data: pointer(testContainer(2))},
 			},
 		}
 
@@ -29,30 +38,93 @@ func TestNodeFind(t *testing.T) {
 		testutil.Equals(t, _find(root, []byte{1, 2}, false), root)
 		testutil.Equals(t, _find(root, []byte{1, 2, 4}, false), (*node)(nil))
 
-		testutil.Equals(t, _find(root, []byte{1, 2, 2}, false).data, pointer(1))
-		testutil.Equals(t, _find(root, []byte{1, 2, 3}, false).data, pointer(2))
+		testutil.Equals(t, _find(root, []byte{1, 2, 2}, false).data, pointer(testContainer(1)))
+		testutil.Equals(t, _find(root, []byte{1, 2, 3}, false).data, pointer(testContainer(2)))
 	})
 
 	t.Run("insert", func(t *testing.T) {
 		var root = &node{
 			key: []byte{1, 2},
 			child: []node{
-				{key: []byte{2}, data: pointer(1)},
-				{key: []byte{3}, data: pointer(2)},
+				{key: []byte{2}, data: pointer(testContainer(1))},
+				{key: []byte{3}, data: pointer(testContainer(2))},
 			},
 		}
 
 		{
 			var n = _find(root, []byte{1, 2, 4}, true)
 			testutil.Equals(t, n.key, []byte{4})
-			n.data = pointer(1)
+			n.data = pointer(testContainer(1))
 		}
 		{
 			var n = _find(root, []byte{1, 2, 4, 5}, true)
 			testutil.Equals(t, n.key, []byte{5})
-			n.data = pointer(2)
+			n.data = pointer(testContainer(2))
 		}
 	})
+
+	// Regression test for a bug where the descent loop took the address of
+	// its range variable (a copy) instead of the matched child in place:
+	// mutations made a level or more below the first match never reached
+	// the real tree, and a second-level mismatch was diagnosed against the
+	// wrong (un-descended) remaining key.
+	t.Run("multiple descents", func(t *testing.T) {
+		var root = &node{
+			key: []byte{1},
+			child: []node{
+				{
+					key: []byte{2},
+					child: []node{
+						{key: []byte{3}, data: pointer(testContainer(1))},
+					},
+				},
+			},
+		}
+
+		// Finding {1, 2, 3} requires descending through two internal
+		// nodes (root, then the {2} node) before reaching the {3} leaf.
+		testutil.Equals(t, _find(root, []byte{1, 2, 3}, false).data, pointer(testContainer(1)))
+		testutil.Equals(t, _find(root, []byte{1, 2, 9}, false), (*node)(nil))
+
+		// Inserting {1, 2, 4} also requires descending through both
+		// internal nodes before diverging from {3} under the {2} node.
+		var n = _find(root, []byte{1, 2, 4}, true)
+		testutil.Equals(t, n.key, []byte{4})
+		n.data = pointer(testContainer(2))
+
+		testutil.Equals(t, _find(root, []byte{1, 2, 4}, false).data, pointer(testContainer(2)))
+		testutil.Equals(t, _find(root, []byte{1, 2, 3}, false).data, pointer(testContainer(1)))
+	})
+}
+
+// TestTree_Insert_KeyIsPrefixOfExisting is a regression test for a bug
+// where inserting a key that is a strict prefix of an already-stored key
+// (e.g. "app" after "apple") made _find return the longer key's own node
+// instead of splitting it, so the new value silently overwrote the longer
+// key's data in place.
+func TestTree_Insert_KeyIsPrefixOfExisting(t *testing.T) {
+	tr := &tree{}
+	tr.insert([]byte("apple"), pointer(testContainer(1)))
+	tr.insert([]byte("app"), pointer(testContainer(2)))
+
+	testutil.Equals(t, 2, tr.len())
+	testutil.Equals(t, pointer(testContainer(1)), tr.find([]byte("apple")))
+	testutil.Equals(t, pointer(testContainer(2)), tr.find([]byte("app")))
+}
+
+// TestTree_Insert_ExistingKeyIsPrefixOfNew checks the opposite direction of
+// TestTree_Insert_KeyIsPrefixOfExisting: inserting a longer key whose
+// leftover suffix, after subtracting an existing key's bytes, happens to
+// restart with one of that key's own bytes (e.g. "aa" then "aaa") must not
+// be mistaken for the new key terminating inside the existing one.
+func TestTree_Insert_ExistingKeyIsPrefixOfNew(t *testing.T) {
+	tr := &tree{}
+	tr.insert([]byte("aa"), pointer(testContainer(1)))
+	tr.insert([]byte("aaa"), pointer(testContainer(2)))
+
+	testutil.Equals(t, 2, tr.len())
+	testutil.Equals(t, pointer(testContainer(1)), tr.find([]byte("aa")))
+	testutil.Equals(t, pointer(testContainer(2)), tr.find([]byte("aaa")))
 }
 
 func TestPrefixLength(t *testing.T) {
@@ -0,0 +1,57 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+// TrimRange removes every member outside [lo, hi) in place. Containers that
+// fall entirely outside the range are dropped wholesale; only the (at most
+// two) boundary containers need per-member filtering. This is meant for
+// enforcing an ID range on a bitmap loaded from an untrusted source, and is
+// faster than calling Remove once per out-of-range ID.
+func (r *RoaringBitmapPosting) TrimRange(lo, hi uint64) {
+	r.checkMutable()
+	r.cardCacheValid = false
+	r.treeCacheValid = false
+	if hi <= lo {
+		r.keys = nil
+		r.containers = nil
+		return
+	}
+	loKey, hiKey := highBits(lo), highBits(hi-1)
+
+	keys := r.keys[:0]
+	containers := r.containers[:0]
+	for i, key := range r.keys {
+		switch {
+		case key < loKey || key > hiKey:
+			continue
+		case key > loKey && key < hiKey:
+			keys = append(keys, key)
+			containers = append(containers, r.containers[i])
+		default:
+			c := r.containers[i]
+			for _, v := range c.toSlice() {
+				id := joinBits(key, v)
+				if id < lo || id >= hi {
+					c = c.remove(v)
+				}
+			}
+			if c.cardinality() > 0 {
+				keys = append(keys, key)
+				containers = append(containers, c)
+			}
+		}
+	}
+	r.keys = keys
+	r.containers = containers
+}
@@ -0,0 +1,32 @@
+// Copyright 2020 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roaring
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/util/testutil"
+)
+
+func TestRoaringBitmapPosting_TrimRange(t *testing.T) {
+	r := NewRoaringBitmapPosting()
+	for _, v := range []uint64{1, 5, 10, 1 << 16, (1 << 16) + 5, 2 << 16} {
+		r.Add(v)
+	}
+
+	r.TrimRange(5, 1<<16+6)
+
+	testutil.Ok(t, r.CheckInvariants())
+	testutil.Equals(t, []uint64{5, 10, 1 << 16, (1 << 16) + 5}, iterateAll(r))
+}